@@ -0,0 +1,374 @@
+package bplustree
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// Node is a B+tree node. Internal nodes hold only separator keys: every
+// key in children[i] is < keys[i] and every key in children[i+1] is
+// >= keys[i]. Leaves hold the values and are linked left-to-right.
+type Node[K, V any] struct {
+	keys     []K
+	values   []V
+	children []*Node[K, V]
+	next     *Node[K, V]
+}
+
+type BPlusTree[K, V any] struct {
+	root    *Node[K, V]
+	order   int
+	size    int
+	compare func(a, b K) int
+}
+
+// New returns an empty tree of the given order whose keys are ordered
+// by their natural ordering.
+func New[K cmp.Ordered, V any](order int) *BPlusTree[K, V] {
+	return NewFunc[K, V](order, cmp.Compare[K])
+}
+
+// NewFunc returns an empty tree of the given order whose keys are ordered
+// by compare, which returns a negative number when a < b, zero when
+// a == b and a positive number when a > b.
+func NewFunc[K, V any](order int, compare func(a, b K) int) *BPlusTree[K, V] {
+	return &BPlusTree[K, V]{
+		root:    nil,
+		order:   order,
+		compare: compare,
+	}
+}
+
+func (t *BPlusTree[K, V]) Len() int {
+	return t.size
+}
+
+// Insert stores value under key. If key is already present its value is
+// replaced and the previous value is returned with true.
+func (t *BPlusTree[K, V]) Insert(key K, value V) (V, bool) {
+	var zero V
+	if t.root == nil {
+		t.root = t.newNode()
+		t.root.keys = append(t.root.keys, key)
+		t.root.values = append(t.root.values, value)
+		t.size++
+		return zero, false
+	}
+
+	leaf := t.findLeaf(key)
+	if index, found := t.searchKeys(key, leaf.keys); found {
+		old := leaf.values[index]
+		leaf.values[index] = value
+		return old, true
+	}
+
+	separator, rightSib, split := t.insertNode(t.root, key, value)
+	if split {
+		// Root overflowed: split it and make tree taller
+		newRoot := t.newNode()
+		newRoot.keys = append(newRoot.keys, separator)
+		newRoot.children = append(newRoot.children, t.root, rightSib)
+		t.root = newRoot
+	}
+	t.size++
+	return zero, false
+}
+
+func (t *BPlusTree[K, V]) Search(key K) (V, bool) {
+	var zero V
+	if t.root == nil {
+		return zero, false
+	}
+
+	leaf := t.findLeaf(key)
+	index, found := t.searchKeys(key, leaf.keys)
+	if !found {
+		return zero, false
+	}
+	return leaf.values[index], true
+}
+
+func (t *BPlusTree[K, V]) Delete(key K) (V, bool) {
+	if t.root == nil {
+		var zero V
+		return zero, false
+	}
+
+	value, found := t.deleteNode(t.root, key)
+	if !found {
+		return value, false
+	}
+	t.size--
+
+	if len(t.root.keys) == 0 {
+		// Root emptied: make tree shorter
+		if len(t.root.children) == 0 {
+			t.root = nil
+		} else {
+			t.root = t.root.children[0]
+		}
+	}
+	return value, true
+}
+
+// All returns an iterator over every key/value pair in ascending key order.
+func (t *BPlusTree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if t.root == nil {
+			return
+		}
+		leaf := t.root
+		for len(leaf.children) > 0 {
+			leaf = leaf.children[0]
+		}
+		t.scan(leaf, 0, yield)
+	}
+}
+
+// Ascend returns an iterator over the pairs with key >= from, in ascending key order.
+func (t *BPlusTree[K, V]) Ascend(from K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if t.root == nil {
+			return
+		}
+		leaf := t.findLeaf(from)
+		index, _ := t.searchKeys(from, leaf.keys)
+		t.scan(leaf, index, yield)
+	}
+}
+
+// Range returns an iterator over the pairs with lo <= key < hi, in ascending key order.
+func (t *BPlusTree[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range t.Ascend(lo) {
+			if t.compare(k, hi) >= 0 || !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+func (t *BPlusTree[K, V]) scan(leaf *Node[K, V], index int, yield func(K, V) bool) {
+	// Walk the leaf chain starting at leaf.keys[index]
+	for ; leaf != nil; leaf = leaf.next {
+		for ; index < len(leaf.keys); index++ {
+			if !yield(leaf.keys[index], leaf.values[index]) {
+				return
+			}
+		}
+		index = 0
+	}
+}
+
+func (t *BPlusTree[K, V]) newNode() *Node[K, V] {
+	// One extra slot so a node can overflow by one key before it is split
+	return &Node[K, V]{
+		keys: make([]K, 0, t.order),
+	}
+}
+
+// minKeys is the fewest keys a non-root node may hold: ceil(order/2) - 1.
+func (t *BPlusTree[K, V]) minKeys() int {
+	return (t.order+1)/2 - 1
+}
+
+func (t *BPlusTree[K, V]) searchKeys(key K, keys []K) (int, bool) {
+	// Binary search for the first key >= key
+	return slices.BinarySearchFunc(keys, key, t.compare)
+}
+
+func (t *BPlusTree[K, V]) childIndex(key K, node *Node[K, V]) int {
+	// A key equal to a separator lives in the right-hand child
+	index, found := t.searchKeys(key, node.keys)
+	if found {
+		index++
+	}
+	return index
+}
+
+func (t *BPlusTree[K, V]) findLeaf(key K) *Node[K, V] {
+	node := t.root
+	for len(node.children) > 0 {
+		node = node.children[t.childIndex(key, node)]
+	}
+	return node
+}
+
+func (t *BPlusTree[K, V]) insertNode(node *Node[K, V], key K, value V) (K, *Node[K, V], bool) {
+	// Insert a new key into the subtree rooted at node. If node overflows it
+	// is split and the separator and new right sibling are returned.
+	var zero K
+	if len(node.children) == 0 {
+		// Leaf node
+		index, _ := t.searchKeys(key, node.keys)
+		node.keys = slices.Insert(node.keys, index, key)
+		node.values = slices.Insert(node.values, index, value)
+		if len(node.keys) < t.order {
+			return zero, nil, false
+		}
+		return t.splitLeaf(node)
+	}
+
+	// Internal node
+	index := t.childIndex(key, node)
+	separator, rightSib, split := t.insertNode(node.children[index], key, value)
+	if split {
+		node.keys = slices.Insert(node.keys, index, separator)
+		node.children = slices.Insert(node.children, index+1, rightSib)
+	}
+	if len(node.keys) < t.order {
+		return zero, nil, false
+	}
+	return t.splitInternal(node)
+}
+
+func (t *BPlusTree[K, V]) splitLeaf(node *Node[K, V]) (K, *Node[K, V], bool) {
+	// The right half keeps its first key; a copy of it becomes the separator
+	mid := len(node.keys) / 2
+
+	rightSib := t.newNode()
+	rightSib.keys = append(rightSib.keys, node.keys[mid:]...)
+	rightSib.values = append(rightSib.values, node.values[mid:]...)
+	clear(node.keys[mid:])
+	clear(node.values[mid:])
+	node.keys = node.keys[:mid]
+	node.values = node.values[:mid]
+
+	rightSib.next = node.next
+	node.next = rightSib
+
+	return rightSib.keys[0], rightSib, true
+}
+
+func (t *BPlusTree[K, V]) splitInternal(node *Node[K, V]) (K, *Node[K, V], bool) {
+	// The middle separator moves up and is not kept in either half
+	mid := len(node.keys) / 2
+
+	rightSib := t.newNode()
+	separator := node.keys[mid]
+	rightSib.keys = append(rightSib.keys, node.keys[mid+1:]...)
+	rightSib.children = append(rightSib.children, node.children[mid+1:]...)
+	clear(node.keys[mid:])
+	clear(node.children[mid+1:])
+	node.keys = node.keys[:mid]
+	node.children = node.children[:mid+1]
+
+	return separator, rightSib, true
+}
+
+func (t *BPlusTree[K, V]) deleteNode(node *Node[K, V], key K) (V, bool) {
+	if len(node.children) == 0 {
+		// Leaf node
+		index, found := t.searchKeys(key, node.keys)
+		if !found {
+			var zero V
+			return zero, false
+		}
+		value := node.values[index]
+		node.keys = slices.Delete(node.keys, index, index+1)
+		node.values = slices.Delete(node.values, index, index+1)
+		return value, true
+	}
+
+	// Internal node. Separators equal to a deleted key are left in place:
+	// they still route correctly.
+	index := t.childIndex(key, node)
+	value, found := t.deleteNode(node.children[index], key)
+	if found {
+		t.rebalance(node, index)
+	}
+	return value, found
+}
+
+func (t *BPlusTree[K, V]) rebalance(node *Node[K, V], index int) {
+	// Restore minimum occupancy of node.children[index] after a removal,
+	// preferring to borrow from a sibling and merging only if neither can spare one
+	minKeys := t.minKeys()
+	if len(node.children[index].keys) >= minKeys {
+		return
+	}
+
+	if index > 0 && len(node.children[index-1].keys) > minKeys {
+		t.borrowFromLeft(node, index)
+		return
+	}
+	if index < len(node.children)-1 && len(node.children[index+1].keys) > minKeys {
+		t.borrowFromRight(node, index)
+		return
+	}
+
+	if index > 0 {
+		t.mergeChildren(node, index-1)
+	} else {
+		t.mergeChildren(node, index)
+	}
+}
+
+func (t *BPlusTree[K, V]) borrowFromLeft(node *Node[K, V], index int) {
+	child := node.children[index]
+	leftSib := node.children[index-1]
+	last := len(leftSib.keys) - 1
+
+	if len(child.children) == 0 {
+		// Leaves: move the pair across and make it the new separator
+		child.keys = slices.Insert(child.keys, 0, leftSib.keys[last])
+		child.values = slices.Insert(child.values, 0, leftSib.values[last])
+		leftSib.keys = slices.Delete(leftSib.keys, last, last+1)
+		leftSib.values = slices.Delete(leftSib.values, last, last+1)
+		node.keys[index-1] = child.keys[0]
+		return
+	}
+
+	// Internal: rotate right through the separator
+	child.keys = slices.Insert(child.keys, 0, node.keys[index-1])
+	node.keys[index-1] = leftSib.keys[last]
+	leftSib.keys = slices.Delete(leftSib.keys, last, last+1)
+
+	last = len(leftSib.children) - 1
+	child.children = slices.Insert(child.children, 0, leftSib.children[last])
+	leftSib.children = slices.Delete(leftSib.children, last, last+1)
+}
+
+func (t *BPlusTree[K, V]) borrowFromRight(node *Node[K, V], index int) {
+	child := node.children[index]
+	rightSib := node.children[index+1]
+
+	if len(child.children) == 0 {
+		// Leaves: move the pair across; the sibling's new first key is the separator
+		child.keys = append(child.keys, rightSib.keys[0])
+		child.values = append(child.values, rightSib.values[0])
+		rightSib.keys = slices.Delete(rightSib.keys, 0, 1)
+		rightSib.values = slices.Delete(rightSib.values, 0, 1)
+		node.keys[index] = rightSib.keys[0]
+		return
+	}
+
+	// Internal: rotate left through the separator
+	child.keys = append(child.keys, node.keys[index])
+	node.keys[index] = rightSib.keys[0]
+	rightSib.keys = slices.Delete(rightSib.keys, 0, 1)
+
+	child.children = append(child.children, rightSib.children[0])
+	rightSib.children = slices.Delete(rightSib.children, 0, 1)
+}
+
+func (t *BPlusTree[K, V]) mergeChildren(node *Node[K, V], index int) {
+	// Merge node.children[index+1] into node.children[index] and drop the separator
+	leftSib := node.children[index]
+	rightSib := node.children[index+1]
+
+	if len(leftSib.children) == 0 {
+		leftSib.keys = append(leftSib.keys, rightSib.keys...)
+		leftSib.values = append(leftSib.values, rightSib.values...)
+		leftSib.next = rightSib.next
+	} else {
+		// Internal: the separator comes down between the two halves
+		leftSib.keys = append(leftSib.keys, node.keys[index])
+		leftSib.keys = append(leftSib.keys, rightSib.keys...)
+		leftSib.children = append(leftSib.children, rightSib.children...)
+	}
+
+	node.keys = slices.Delete(node.keys, index, index+1)
+	node.children = slices.Delete(node.children, index+1, index+2)
+}
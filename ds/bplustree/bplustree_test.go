@@ -0,0 +1,253 @@
+package bplustree
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestBPlusTree_InsertAndSearch(t *testing.T) {
+	tree := New[int, string](3)
+
+	tree.Insert(10, "ten")
+	tree.Insert(20, "twenty")
+
+	val, found := tree.Search(10)
+	if !found || val != "ten" {
+		t.Errorf("key 10: found=%v, val=%v", found, val)
+	}
+	if _, found := tree.Search(99); found {
+		t.Error("expected not to find key 99")
+	}
+	if tree.Len() != 2 {
+		t.Errorf("expected len 2, got %d", tree.Len())
+	}
+}
+
+func TestBPlusTree_Insert_Replace(t *testing.T) {
+	tree := New[int, string](3)
+
+	tree.Insert(10, "ten")
+	old, existed := tree.Insert(10, "TEN")
+	if !existed || old != "ten" {
+		t.Errorf("expected previous value 'ten', got %q (existed=%v)", old, existed)
+	}
+	if val, _ := tree.Search(10); val != "TEN" {
+		t.Errorf("expected 'TEN', got %q", val)
+	}
+	if tree.Len() != 1 {
+		t.Errorf("expected len 1, got %d", tree.Len())
+	}
+}
+
+func TestBPlusTree_Split_Leaf(t *testing.T) {
+	tree := New[int, int](3)
+
+	// Insert 3 keys - the leaf overflows and splits
+	tree.Insert(10, 10)
+	tree.Insert(20, 20)
+	tree.Insert(30, 30)
+
+	// After split of order-3 leaf [10, 20, 30]:
+	//        [20]          <- separator is a copy of the right leaf's first key
+	//       /    \
+	//    [10] -> [20, 30]  <- values stay in the leaves
+	if !slices.Equal(tree.root.keys, []int{20}) {
+		t.Fatalf("expected root keys [20], got %v", tree.root.keys)
+	}
+	if len(tree.root.values) != 0 {
+		t.Errorf("expected internal node to hold no values, got %v", tree.root.values)
+	}
+
+	left, right := tree.root.children[0], tree.root.children[1]
+	if !slices.Equal(left.keys, []int{10}) || !slices.Equal(right.keys, []int{20, 30}) {
+		t.Errorf("expected leaves [10] and [20 30], got %v and %v", left.keys, right.keys)
+	}
+	if left.next != right || right.next != nil {
+		t.Error("expected leaves to be linked left-to-right")
+	}
+}
+
+func TestBPlusTree_All(t *testing.T) {
+	tree := New[int, int](4)
+	for _, k := range rand.New(rand.NewPCG(1, 2)).Perm(200) {
+		tree.Insert(k, k*10)
+	}
+
+	var got []int
+	for k, v := range tree.All() {
+		if v != k*10 {
+			t.Fatalf("key %d: expected %d, got %d", k, k*10, v)
+		}
+		got = append(got, k)
+	}
+	if len(got) != 200 || !slices.IsSorted(got) {
+		t.Errorf("expected 200 sorted keys, got %v", got)
+	}
+}
+
+func TestBPlusTree_AscendAndRange(t *testing.T) {
+	tree := New[int, int](3)
+	for i := 0; i < 100; i += 2 {
+		tree.Insert(i, i)
+	}
+
+	var got []int
+	for k := range tree.Ascend(91) {
+		got = append(got, k)
+	}
+	if !slices.Equal(got, []int{92, 94, 96, 98}) {
+		t.Errorf("Ascend(91): got %v", got)
+	}
+
+	got = got[:0]
+	for k := range tree.Range(10, 20) {
+		got = append(got, k)
+	}
+	if !slices.Equal(got, []int{10, 12, 14, 16, 18}) {
+		t.Errorf("Range(10, 20): got %v", got)
+	}
+
+	got = got[:0]
+	for k := range tree.Range(0, 100) {
+		if k > 4 {
+			break
+		}
+		got = append(got, k)
+	}
+	if !slices.Equal(got, []int{0, 2, 4}) {
+		t.Errorf("expected early stop after 4, got %v", got)
+	}
+}
+
+func TestBPlusTree_Delete(t *testing.T) {
+	tree := New[int, int](3)
+	for i := 0; i < 20; i++ {
+		tree.Insert(i, i)
+	}
+
+	if val, found := tree.Delete(7); !found || val != 7 {
+		t.Errorf("expected to delete 7, got found=%v, val=%v", found, val)
+	}
+	if _, found := tree.Delete(7); found {
+		t.Error("expected second delete of 7 to fail")
+	}
+	if _, found := tree.Search(7); found {
+		t.Error("expected key 7 to be gone")
+	}
+	checkInvariants(t, tree)
+
+	for i := 0; i < 20; i++ {
+		tree.Delete(i)
+		checkInvariants(t, tree)
+	}
+	if tree.root != nil || tree.Len() != 0 {
+		t.Errorf("expected empty tree, got len %d", tree.Len())
+	}
+}
+
+func TestBPlusTree_RandomInsertDelete(t *testing.T) {
+	for _, order := range []int{3, 4, 5, 8} {
+		rng := rand.New(rand.NewPCG(uint64(order), 7))
+		tree := New[int, int](order)
+		expected := make(map[int]int)
+
+		for i := 0; i < 5000; i++ {
+			k := rng.IntN(400)
+			if rng.IntN(3) == 0 {
+				val, found := tree.Delete(k)
+				want, ok := expected[k]
+				if found != ok || (found && val != want) {
+					t.Fatalf("order %d: Delete(%d) = %v, %v; want %v, %v", order, k, val, found, want, ok)
+				}
+				delete(expected, k)
+			} else {
+				tree.Insert(k, i)
+				expected[k] = i
+			}
+			checkInvariants(t, tree)
+		}
+
+		if tree.Len() != len(expected) {
+			t.Fatalf("order %d: expected len %d, got %d", order, len(expected), tree.Len())
+		}
+		for k, want := range expected {
+			if val, found := tree.Search(k); !found || val != want {
+				t.Fatalf("order %d: key %d: found=%v, val=%v, want %v", order, k, found, val, want)
+			}
+		}
+	}
+}
+
+// checkInvariants verifies separator bounds, occupancy, uniform leaf depth
+// and that the leaf chain visits every key in order.
+func checkInvariants[K, V any](t *testing.T, tree *BPlusTree[K, V]) {
+	t.Helper()
+	if tree.root == nil {
+		return
+	}
+
+	var leaves []*Node[K, V]
+	leafDepth := -1
+	var walk func(node *Node[K, V], depth int, lo, hi *K)
+	walk = func(node *Node[K, V], depth int, lo, hi *K) {
+		if node != tree.root && len(node.keys) < tree.minKeys() {
+			t.Fatalf("node %v has %d keys, below minimum %d", node.keys, len(node.keys), tree.minKeys())
+		}
+		if len(node.keys) > tree.order-1 {
+			t.Fatalf("node %v has %d keys, above maximum %d", node.keys, len(node.keys), tree.order-1)
+		}
+		for i, k := range node.keys {
+			if (i > 0 && tree.compare(node.keys[i-1], k) >= 0) ||
+				(lo != nil && tree.compare(k, *lo) < 0) ||
+				(hi != nil && tree.compare(k, *hi) >= 0) {
+				t.Fatalf("node %v is out of order", node.keys)
+			}
+		}
+
+		if len(node.children) == 0 {
+			if len(node.values) != len(node.keys) {
+				t.Fatalf("leaf %v has %d values", node.keys, len(node.values))
+			}
+			if leafDepth < 0 {
+				leafDepth = depth
+			} else if depth != leafDepth {
+				t.Fatalf("leaf at depth %d, expected %d", depth, leafDepth)
+			}
+			leaves = append(leaves, node)
+			return
+		}
+		if len(node.values) != 0 || len(node.children) != len(node.keys)+1 {
+			t.Fatalf("internal node %v has %d values and %d children", node.keys, len(node.values), len(node.children))
+		}
+		for i, child := range node.children {
+			childLo, childHi := lo, hi
+			if i > 0 {
+				childLo = &node.keys[i-1]
+			}
+			if i < len(node.keys) {
+				childHi = &node.keys[i]
+			}
+			walk(child, depth+1, childLo, childHi)
+		}
+	}
+	walk(tree.root, 0, nil, nil)
+
+	for i, leaf := range leaves {
+		var want *Node[K, V]
+		if i+1 < len(leaves) {
+			want = leaves[i+1]
+		}
+		if leaf.next != want {
+			t.Fatalf("leaf %v is not linked to its right neighbour", leaf.keys)
+		}
+	}
+
+	count := 0
+	for range tree.All() {
+		count++
+	}
+	if count != tree.Len() {
+		t.Fatalf("leaf chain has %d keys, expected %d", count, tree.Len())
+	}
+}
@@ -0,0 +1,118 @@
+package btree
+
+import (
+	"math/rand/v2"
+	"sync"
+	"testing"
+)
+
+// lockedBTree is the global-lock wrapper that ConcurrentBTree replaces.
+type lockedBTree struct {
+	mu   sync.RWMutex
+	tree *BTree[int, int]
+}
+
+func (l *lockedBTree) Insert(key, value int) {
+	l.mu.Lock()
+	l.tree.Insert(key, value)
+	l.mu.Unlock()
+}
+
+func (l *lockedBTree) Delete(key int) {
+	l.mu.Lock()
+	l.tree.Delete(key)
+	l.mu.Unlock()
+}
+
+func (l *lockedBTree) Search(key int) (int, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.tree.Search(key)
+}
+
+type index interface {
+	Insert(key, value int)
+	Delete(key int)
+	Search(key int) (int, bool)
+}
+
+type concurrentIndex struct {
+	tree *ConcurrentBTree[int, int]
+}
+
+func (c concurrentIndex) Insert(key, value int) { c.tree.Insert(key, value) }
+func (c concurrentIndex) Delete(key int)        { c.tree.Delete(key) }
+func (c concurrentIndex) Search(key int) (int, bool) {
+	return c.tree.Search(key)
+}
+
+const benchKeys = 100000
+
+// benchMixed runs a parallel workload where writePct percent of the
+// operations are inserts or deletes and the rest are lookups.
+func benchMixed(b *testing.B, idx index, writePct int) {
+	for k := 0; k < benchKeys; k += 2 {
+		idx.Insert(k, k)
+	}
+	var seed uint64
+	var mu sync.Mutex
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		mu.Lock()
+		seed++
+		rng := rand.New(rand.NewPCG(seed, seed))
+		mu.Unlock()
+		for pb.Next() {
+			k := rng.IntN(benchKeys)
+			switch op := rng.IntN(100); {
+			case op >= writePct:
+				idx.Search(k)
+			case op%2 == 0:
+				idx.Insert(k, k)
+			default:
+				idx.Delete(k)
+			}
+		}
+	})
+}
+
+func BenchmarkConcurrentBTree_ReadHeavy(b *testing.B) {
+	benchMixed(b, concurrentIndex{NewConcurrent[int, int](32)}, 10)
+}
+
+func BenchmarkLockedBTree_ReadHeavy(b *testing.B) {
+	benchMixed(b, &lockedBTree{tree: New[int, int](32)}, 10)
+}
+
+func BenchmarkConcurrentBTree_WriteHeavy(b *testing.B) {
+	benchMixed(b, concurrentIndex{NewConcurrent[int, int](32)}, 50)
+}
+
+func BenchmarkLockedBTree_WriteHeavy(b *testing.B) {
+	benchMixed(b, &lockedBTree{tree: New[int, int](32)}, 50)
+}
+
+func BenchmarkConcurrentBTree_WriteOnly(b *testing.B) {
+	benchMixed(b, concurrentIndex{NewConcurrent[int, int](32)}, 100)
+}
+
+func BenchmarkLockedBTree_WriteOnly(b *testing.B) {
+	benchMixed(b, &lockedBTree{tree: New[int, int](32)}, 100)
+}
+
+func BenchmarkBTree_InsertSorted(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		tree := New[int, int](32)
+		for k := 0; k < benchKeys; k++ {
+			tree.Insert(k, k)
+		}
+	}
+}
+
+func BenchmarkBTree_BulkLoad(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		tree := New[int, int](32)
+		tree.BulkLoad(sortedSeq(benchKeys), 1)
+	}
+}
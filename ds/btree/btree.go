@@ -1,82 +1,175 @@
 package btree
 
-import "slices"
+import (
+	"cmp"
+	"slices"
+)
 
-type Entry struct {
-	key   int
-	value any
+type Entry[K, V any] struct {
+	key   K
+	value V
+	// dups holds further values for key, in insertion order, when the
+	// tree is a multimap
+	dups []V
 }
 
-type Node struct {
-	entries  []Entry
-	children []*Node
+type Node[K, V any] struct {
+	entries  []Entry[K, V]
+	children []*Node[K, V]
+	// count is the number of keys in the subtree rooted at the node
+	count int
+	// cow identifies the tree generation that owns the node. Nodes from an
+	// older generation may be shared with snapshots and are copied before
+	// being modified.
+	cow *copyOnWriteContext
 }
 
-type BTree struct {
-	root  *Node
-	order int
+type BTree[K, V any] struct {
+	root    *Node[K, V]
+	order   int
+	compare func(a, b K) int
+	multi   bool
+	cow     *copyOnWriteContext
 }
 
-func New(order int) *BTree {
-	return &BTree{
-		root:  nil,
-		order: order,
+type copyOnWriteContext struct {
+	// Non-zero size so every context has a distinct address
+	_ byte
+}
+
+type config struct {
+	multi bool
+}
+
+// Option configures a BTree.
+type Option func(*config)
+
+// WithMultimap makes the tree keep every value inserted for a key instead
+// of replacing the previous one. Search returns the oldest value for a
+// key, SearchAll returns all of them and Delete removes them all.
+func WithMultimap() Option {
+	return func(c *config) {
+		c.multi = true
+	}
+}
+
+// New returns an empty tree of the given order whose keys are ordered
+// by their natural ordering.
+func New[K cmp.Ordered, V any](order int, opts ...Option) *BTree[K, V] {
+	return NewFunc[K, V](order, cmp.Compare[K], opts...)
+}
+
+// NewFunc returns an empty tree of the given order whose keys are ordered
+// by compare, which returns a negative number when a < b, zero when
+// a == b and a positive number when a > b.
+func NewFunc[K, V any](order int, compare func(a, b K) int, opts ...Option) *BTree[K, V] {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &BTree[K, V]{
+		root:    nil,
+		order:   order,
+		compare: compare,
+		multi:   cfg.multi,
+		cow:     new(copyOnWriteContext),
 	}
 }
 
-func (b *BTree) Insert(key int, value any) {
-	entry := Entry{
+// Insert stores value under key. If key is already present its value is
+// replaced (or, for a multimap, value is appended to its values) and the
+// previous (oldest) value is returned with true.
+func (b *BTree[K, V]) Insert(key K, value V) (V, bool) {
+	if b.searchNode(key, b.root) != nil {
+		return b.replaceValue(key, value), true
+	}
+
+	var zero V
+	entry := Entry[K, V]{
 		key:   key,
 		value: value,
 	}
 	if b.root == nil {
-		b.root = &Node{
-			entries:  make([]Entry, 0, b.order-1),
-			children: make([]*Node, 0, b.order),
-		}
+		b.root = b.newNode()
 		b.root.entries = append(b.root.entries, entry)
-		return
+		b.root.count = 1
+		return zero, false
 	}
 
-	if len(b.root.entries) >= b.order-1 {
-		// split root and make tree taller
-		newRoot := &Node{
-			entries:  make([]Entry, 0, b.order-1),
-			children: make([]*Node, 0, b.order),
-		}
-
-		// Move old root to new root's children
-		newRoot.children = append(newRoot.children, b.root)
+	b.root = b.mutable(b.root)
+	promoted, rightSib, split := b.insertNode(b.root, entry)
+	if split {
+		// Root overflowed: split it and make tree taller
+		newRoot := b.newNode()
+		newRoot.entries = append(newRoot.entries, promoted)
+		newRoot.children = append(newRoot.children, b.root, rightSib)
+		newRoot.count = b.root.count + rightSib.count + 1
 		b.root = newRoot
+	}
+	return zero, false
+}
 
-		b.splitChild(b.root, 0)
+// Delete removes key and returns its value. For a multimap all values
+// for key are removed and the oldest one is returned.
+func (b *BTree[K, V]) Delete(key K) (V, bool) {
+	if b.searchNode(key, b.root) == nil {
+		// Checked up front so a miss copies nothing
+		var zero V
+		return zero, false
+	}
+
+	b.root = b.mutable(b.root)
+	value, found := b.deleteNode(b.root, key)
+	if len(b.root.entries) == 0 {
+		// Root emptied by a merge (or last key removed): make tree shorter
+		if len(b.root.children) == 0 {
+			b.root = nil
+		} else {
+			b.root = b.root.children[0]
+		}
 	}
+	return value, found
+}
 
-	b.insertNonFull(b.root, entry)
+func (b *BTree[K, V]) Search(key K) (V, bool) {
+	entry := b.searchNode(key, b.root)
+	if entry == nil {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
 }
 
-func (b *BTree) Search(key int) (any, bool) {
-	if b.root == nil {
-		return nil, false
+// SearchAll returns every value stored under key in insertion order, or
+// nil if key is absent. Outside multimap mode there is at most one.
+func (b *BTree[K, V]) SearchAll(key K) []V {
+	entry := b.searchNode(key, b.root)
+	if entry == nil {
+		return nil
 	}
-	return b.searchNode(key, b.root)
+	values := make([]V, 0, 1+len(entry.dups))
+	values = append(values, entry.value)
+	return append(values, entry.dups...)
 }
 
-func (b *BTree) searchNode(key int, node *Node) (any, bool) {
+func (b *BTree[K, V]) searchNode(key K, node *Node[K, V]) *Entry[K, V] {
 	// Recursive search through node and its children
+	if node == nil {
+		return nil
+	}
 	index, found := b.searchEntries(key, node.entries)
 	if found {
-		return node.entries[index].value, true
+		return &node.entries[index]
 	}
 
 	if len(node.children) == 0 {
-		return nil, false
+		return nil
 	}
 	return b.searchNode(key, node.children[index])
 
 }
 
-func (b *BTree) searchEntries(key int, entries []Entry) (int, bool) {
+func (b *BTree[K, V]) searchEntries(key K, entries []Entry[K, V]) (int, bool) {
 	// Binary search for the index of the child pointer
 	// If the key is found, return the index and true
 	// If the key is not found, return the index of the child pointer to the left of the key
@@ -87,9 +180,10 @@ func (b *BTree) searchEntries(key int, entries []Entry) (int, bool) {
 
 	for left <= right {
 		mid := (left + right) / 2
-		if entries[mid].key == key {
+		c := b.compare(entries[mid].key, key)
+		if c == 0 {
 			return mid, true
-		} else if entries[mid].key < key {
+		} else if c < 0 {
 			left = mid + 1
 		} else {
 			right = mid - 1
@@ -98,46 +192,234 @@ func (b *BTree) searchEntries(key int, entries []Entry) (int, bool) {
 	return left, false
 }
 
-func (b *BTree) splitChild(node *Node, index int) {
-	// Only split if there's a single child and it is full
-	mid := (b.order - 1) / 2
-
-	rightSib := &Node{
-		entries:  make([]Entry, 0, b.order-1),
-		children: make([]*Node, 0, b.order),
+func (b *BTree[K, V]) newNode() *Node[K, V] {
+	// One extra slot so a node can overflow by one entry before it is split
+	return &Node[K, V]{
+		entries:  make([]Entry[K, V], 0, b.order),
+		children: make([]*Node[K, V], 0, b.order+1),
+		cow:      b.cow,
 	}
+}
 
-	leftSib := node.children[index]
+func (b *BTree[K, V]) mutable(node *Node[K, V]) *Node[K, V] {
+	// Return node if this tree owns it, otherwise a private copy
+	if node.cow == b.cow {
+		return node
+	}
+	clone := b.newNode()
+	clone.entries = append(clone.entries, node.entries...)
+	clone.children = append(clone.children, node.children...)
+	clone.count = node.count
+	return clone
+}
 
-	promotedEntry := leftSib.entries[mid]
-	rightSib.entries = append(rightSib.entries, leftSib.entries[mid+1:]...)
-	leftSib.entries = leftSib.entries[:mid]
+func (b *BTree[K, V]) mutableChild(node *Node[K, V], index int) *Node[K, V] {
+	// node must already be mutable
+	child := b.mutable(node.children[index])
+	node.children[index] = child
+	return child
+}
 
-	if len(leftSib.children) > 0 {
-		rightSib.children = append(rightSib.children, leftSib.children[mid+1:]...)
-		leftSib.children = leftSib.children[:mid+1]
+func (b *BTree[K, V]) replaceValue(key K, value V) V {
+	// Copy the path down to key's (known to exist) entry and update it there
+	b.root = b.mutable(b.root)
+	node := b.root
+	for {
+		index, found := b.searchEntries(key, node.entries)
+		if found {
+			entry := &node.entries[index]
+			old := entry.value
+			if b.multi {
+				// Clip so a snapshot sharing the old slice never sees the append
+				entry.dups = append(slices.Clip(entry.dups), value)
+			} else {
+				entry.value = value
+			}
+			return old
+		}
+		node = b.mutableChild(node, index)
 	}
+}
 
-	// node.entries = append(node.entries, promotedEntry)
-	// node.children = append(node.children, rightSib)
-	node.entries = slices.Insert(node.entries, index, promotedEntry)
-	node.children = slices.Insert(node.children, index+1, rightSib)
+// minEntries is the fewest entries a non-root node may hold: ceil(order/2) - 1.
+func (b *BTree[K, V]) minEntries() int {
+	return (b.order+1)/2 - 1
 }
 
-func (b *BTree) insertNonFull(node *Node, entry Entry) {
+func (b *BTree[K, V]) insertNode(node *Node[K, V], entry Entry[K, V]) (Entry[K, V], *Node[K, V], bool) {
+	// Insert into the subtree rooted at node. If node overflows it is split
+	// and the promoted entry and new right sibling are returned to the parent.
+	index, _ := b.searchEntries(entry.key, node.entries)
 	if len(node.children) == 0 {
 		// Leaf node
-		index, _ := b.searchEntries(entry.key, node.entries)
 		node.entries = slices.Insert(node.entries, index, entry)
 	} else {
 		// Internal node
+		promoted, rightSib, split := b.insertNode(b.mutableChild(node, index), entry)
+		if split {
+			node.entries = slices.Insert(node.entries, index, promoted)
+			node.children = slices.Insert(node.children, index+1, rightSib)
+		}
+	}
+	node.count++
+
+	if len(node.entries) < b.order {
+		return Entry[K, V]{}, nil, false
+	}
+	return b.splitNode(node)
+}
+
+func (b *BTree[K, V]) splitNode(node *Node[K, V]) (Entry[K, V], *Node[K, V], bool) {
+	// Split an overflowing node (order entries) around its middle entry.
+	// Both halves end up with at least minEntries entries.
+	mid := len(node.entries) / 2
+
+	rightSib := b.newNode()
+
+	promotedEntry := node.entries[mid]
+	rightSib.entries = append(rightSib.entries, node.entries[mid+1:]...)
+	clear(node.entries[mid:])
+	node.entries = node.entries[:mid]
+
+	if len(node.children) > 0 {
+		rightSib.children = append(rightSib.children, node.children[mid+1:]...)
+		clear(node.children[mid+1:])
+		node.children = node.children[:mid+1]
+	}
+	rightSib.count = len(rightSib.entries)
+	for _, child := range rightSib.children {
+		rightSib.count += child.count
+	}
+	node.count -= rightSib.count + 1
+
+	return promotedEntry, rightSib, true
+}
 
-		// Find the child to insert into
-		index, _ := b.searchEntries(entry.key, node.entries)
-		if len(node.children[index].entries) >= b.order-1 {
-			b.splitChild(node, index)
-			index, _ = b.searchEntries(entry.key, node.entries)
+func (b *BTree[K, V]) deleteNode(node *Node[K, V], key K) (V, bool) {
+	index, found := b.searchEntries(key, node.entries)
+	if len(node.children) == 0 {
+		// Leaf node
+		if !found {
+			var zero V
+			return zero, false
+		}
+		value := node.entries[index].value
+		node.entries = slices.Delete(node.entries, index, index+1)
+		node.count--
+		return value, true
+	}
+
+	// Internal node
+	var value V
+	child := b.mutableChild(node, index)
+	if found {
+		// Replace with the in-order predecessor, which always lives in a leaf
+		value = node.entries[index].value
+		node.entries[index] = b.deleteMax(child)
+	} else {
+		var ok bool
+		value, ok = b.deleteNode(child, key)
+		if !ok {
+			return value, false
 		}
-		b.insertNonFull(node.children[index], entry)
 	}
+	node.count--
+	b.rebalance(node, index)
+	return value, true
+}
+
+func (b *BTree[K, V]) deleteMax(node *Node[K, V]) Entry[K, V] {
+	// Remove and return the largest entry in the subtree rooted at node
+	if len(node.children) == 0 {
+		last := len(node.entries) - 1
+		entry := node.entries[last]
+		node.entries = slices.Delete(node.entries, last, last+1)
+		node.count--
+		return entry
+	}
+
+	index := len(node.children) - 1
+	entry := b.deleteMax(b.mutableChild(node, index))
+	node.count--
+	b.rebalance(node, index)
+	return entry
+}
+
+func (b *BTree[K, V]) rebalance(node *Node[K, V], index int) {
+	// Restore minimum occupancy of node.children[index] after a removal,
+	// preferring to borrow from a sibling and merging only if neither can spare one
+	minEntries := b.minEntries()
+	if len(node.children[index].entries) >= minEntries {
+		return
+	}
+
+	if index > 0 && len(node.children[index-1].entries) > minEntries {
+		b.borrowFromLeft(node, index)
+		return
+	}
+	if index < len(node.children)-1 && len(node.children[index+1].entries) > minEntries {
+		b.borrowFromRight(node, index)
+		return
+	}
+
+	if index > 0 {
+		b.mergeChildren(node, index-1)
+	} else {
+		b.mergeChildren(node, index)
+	}
+}
+
+func (b *BTree[K, V]) borrowFromLeft(node *Node[K, V], index int) {
+	// Rotate right: separator moves down into child, left sibling's last entry moves up
+	child := node.children[index]
+	leftSib := b.mutableChild(node, index-1)
+
+	last := len(leftSib.entries) - 1
+	child.entries = slices.Insert(child.entries, 0, node.entries[index-1])
+	node.entries[index-1] = leftSib.entries[last]
+	leftSib.entries = slices.Delete(leftSib.entries, last, last+1)
+	moved := 1
+
+	if len(leftSib.children) > 0 {
+		last = len(leftSib.children) - 1
+		moved += leftSib.children[last].count
+		child.children = slices.Insert(child.children, 0, leftSib.children[last])
+		leftSib.children = slices.Delete(leftSib.children, last, last+1)
+	}
+	child.count += moved
+	leftSib.count -= moved
+}
+
+func (b *BTree[K, V]) borrowFromRight(node *Node[K, V], index int) {
+	// Rotate left: separator moves down into child, right sibling's first entry moves up
+	child := node.children[index]
+	rightSib := b.mutableChild(node, index+1)
+
+	child.entries = append(child.entries, node.entries[index])
+	node.entries[index] = rightSib.entries[0]
+	rightSib.entries = slices.Delete(rightSib.entries, 0, 1)
+	moved := 1
+
+	if len(rightSib.children) > 0 {
+		moved += rightSib.children[0].count
+		child.children = append(child.children, rightSib.children[0])
+		rightSib.children = slices.Delete(rightSib.children, 0, 1)
+	}
+	child.count += moved
+	rightSib.count -= moved
+}
+
+func (b *BTree[K, V]) mergeChildren(node *Node[K, V], index int) {
+	// Merge node.children[index+1] and the separator between them into node.children[index].
+	// The right sibling is discarded, so only the left one needs to be mutable.
+	leftSib := b.mutableChild(node, index)
+	rightSib := node.children[index+1]
+
+	leftSib.entries = append(leftSib.entries, node.entries[index])
+	leftSib.entries = append(leftSib.entries, rightSib.entries...)
+	leftSib.children = append(leftSib.children, rightSib.children...)
+	leftSib.count += rightSib.count + 1
+
+	node.entries = slices.Delete(node.entries, index, index+1)
+	node.children = slices.Delete(node.children, index+1, index+2)
 }
@@ -1,16 +1,24 @@
 package btree
 
-import "testing"
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
 
 func TestBTree_NewTree(t *testing.T) {
-	tree := New(3) // order 3 = max 2 keys per node, max 3 children
+	tree := New[int, int](3) // order 3 = max 2 keys per node, max 3 children
 	if tree == nil {
 		t.Fatal("expected non-nil tree")
 	}
 }
 
 func TestBTree_InsertAndSearch_Single(t *testing.T) {
-	tree := New(3)
+	tree := New[int, string](3)
 
 	tree.Insert(10, "ten")
 
@@ -24,7 +32,7 @@ func TestBTree_InsertAndSearch_Single(t *testing.T) {
 }
 
 func TestBTree_Search_NotFound(t *testing.T) {
-	tree := New(3)
+	tree := New[int, string](3)
 
 	tree.Insert(10, "ten")
 
@@ -35,7 +43,7 @@ func TestBTree_Search_NotFound(t *testing.T) {
 }
 
 func TestBTree_InsertAndSearch_Multiple_NoSplit(t *testing.T) {
-	tree := New(3) // max 2 keys per node
+	tree := New[int, string](3) // max 2 keys per node
 
 	tree.Insert(10, "ten")
 	tree.Insert(20, "twenty")
@@ -53,7 +61,7 @@ func TestBTree_InsertAndSearch_Multiple_NoSplit(t *testing.T) {
 }
 
 func TestBTree_Insert_MaintainsOrder(t *testing.T) {
-	tree := New(3)
+	tree := New[int, string](3)
 
 	// Insert out of order
 	tree.Insert(30, "thirty")
@@ -70,7 +78,7 @@ func TestBTree_Insert_MaintainsOrder(t *testing.T) {
 }
 
 func TestBTree_Insert_Split_Root(t *testing.T) {
-	tree := New(3) // order 3 = max 2 keys per node
+	tree := New[int, string](3) // order 3 = max 2 keys per node
 
 	// Insert 3 keys - this MUST trigger a split
 	tree.Insert(10, "ten")
@@ -123,7 +131,7 @@ func TestBTree_Insert_Split_Root(t *testing.T) {
 }
 
 func TestBTree_Insert_Split_HigherOrder(t *testing.T) {
-	tree := New(5) // order 5 = max 4 keys per node, max 5 children
+	tree := New[int, string](5) // order 5 = max 4 keys per node, max 5 children
 
 	// Insert 5 keys - triggers split on 5th insert
 	tree.Insert(10, "ten")
@@ -169,7 +177,7 @@ func TestBTree_Insert_Split_HigherOrder(t *testing.T) {
 }
 
 func TestBTree_Insert_MultipleSplits(t *testing.T) {
-	tree := New(3) // order 3 = small nodes, frequent splits
+	tree := New[int, int](3) // order 3 = small nodes, frequent splits
 
 	// Insert 7 keys - will cause multiple splits
 	keys := []int{10, 20, 30, 40, 50, 60, 70}
@@ -204,7 +212,7 @@ func TestBTree_Insert_MultipleSplits(t *testing.T) {
 }
 
 func TestBTree_Insert_ManyKeys(t *testing.T) {
-	tree := New(4) // order 4
+	tree := New[int, int](4) // order 4
 
 	// Insert 100 keys
 	for i := 0; i < 100; i++ {
@@ -234,7 +242,7 @@ func TestBTree_Insert_ManyKeys(t *testing.T) {
 }
 
 func TestBTree_Insert_NonSequential(t *testing.T) {
-	tree := New(3)
+	tree := New[int, int](3)
 
 	// Insert in an order that forces splitting a non-last child
 	// After inserting 50, 30, 70: tree is root=[50], children=[[30], [70]]
@@ -252,3 +260,360 @@ func TestBTree_Insert_NonSequential(t *testing.T) {
 		}
 	}
 }
+
+func TestBTree_Insert_ReplacesExisting(t *testing.T) {
+	tree := New[int, string](3)
+
+	if old, existed := tree.Insert(10, "ten"); existed {
+		t.Errorf("expected new key, got previous value %q", old)
+	}
+	old, existed := tree.Insert(10, "TEN")
+	if !existed || old != "ten" {
+		t.Errorf("expected previous value 'ten', got %q (existed=%v)", old, existed)
+	}
+
+	if val, _ := tree.Search(10); val != "TEN" {
+		t.Errorf("expected 'TEN', got %q", val)
+	}
+	if n := len(tree.root.entries); n != 1 {
+		t.Errorf("expected 1 entry, got %d", n)
+	}
+}
+
+func TestBTree_Insert_ReplacesInInternalNode(t *testing.T) {
+	tree := New[int, int](3)
+	for i := 0; i < 50; i++ {
+		tree.Insert(i, i)
+	}
+	for i := 0; i < 50; i++ {
+		if old, existed := tree.Insert(i, i*100); !existed || old != i {
+			t.Fatalf("key %d: expected previous value %d, got %d (existed=%v)", i, i, old, existed)
+		}
+	}
+	checkInvariants(t, tree)
+
+	count := 0
+	for k, v := range tree.All() {
+		if v != k*100 {
+			t.Errorf("key %d: expected %d, got %d", k, k*100, v)
+		}
+		count++
+	}
+	if count != 50 {
+		t.Errorf("expected 50 entries, got %d", count)
+	}
+}
+
+func TestBTree_Multimap(t *testing.T) {
+	tree := New[string, int](3, WithMultimap())
+
+	tree.Insert("a", 1)
+	if old, existed := tree.Insert("a", 2); !existed || old != 1 {
+		t.Errorf("expected previous value 1, got %d (existed=%v)", old, existed)
+	}
+	tree.Insert("b", 10)
+	tree.Insert("a", 3)
+	checkInvariants(t, tree)
+
+	if val, found := tree.Search("a"); !found || val != 1 {
+		t.Errorf("expected Search to return oldest value 1, got %d", val)
+	}
+	if got := tree.SearchAll("a"); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("expected [1 2 3], got %v", got)
+	}
+	if got := tree.SearchAll("b"); !slices.Equal(got, []int{10}) {
+		t.Errorf("expected [10], got %v", got)
+	}
+	if got := tree.SearchAll("c"); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+
+	var pairs []string
+	for k, v := range tree.All() {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", k, v))
+	}
+	if want := []string{"a=1", "a=2", "a=3", "b=10"}; !slices.Equal(pairs, want) {
+		t.Errorf("expected %v, got %v", want, pairs)
+	}
+
+	if val, found := tree.Delete("a"); !found || val != 1 {
+		t.Errorf("expected Delete to return 1, got %d (found=%v)", val, found)
+	}
+	if got := tree.SearchAll("a"); got != nil {
+		t.Errorf("expected all values of 'a' to be gone, got %v", got)
+	}
+}
+
+func TestBTree_SearchAll_NotMultimap(t *testing.T) {
+	tree := New[int, int](3)
+	tree.Insert(1, 1)
+	tree.Insert(1, 2)
+
+	if got := tree.SearchAll(1); !slices.Equal(got, []int{2}) {
+		t.Errorf("expected [2], got %v", got)
+	}
+}
+
+func TestBTree_StringKeys(t *testing.T) {
+	tree := New[string, int](3)
+
+	words := []string{"pear", "apple", "fig", "banana", "cherry", "date"}
+	for i, w := range words {
+		tree.Insert(w, i)
+	}
+	checkInvariants(t, tree)
+
+	for i, w := range words {
+		val, found := tree.Search(w)
+		if !found || val != i {
+			t.Errorf("key %q: found=%v, val=%v", w, found, val)
+		}
+	}
+	if _, found := tree.Search("grape"); found {
+		t.Error("should not find key grape")
+	}
+}
+
+func TestBTree_NewFunc_ByteSliceKeys(t *testing.T) {
+	tree := NewFunc[[]byte, string](4, bytes.Compare)
+
+	for _, k := range []string{"b", "a", "d", "c", "e"} {
+		tree.Insert([]byte(k), k)
+	}
+	checkInvariants(t, tree)
+
+	val, found := tree.Search([]byte("c"))
+	if !found || val != "c" {
+		t.Errorf("key c: found=%v, val=%v", found, val)
+	}
+}
+
+func TestBTree_NewFunc_TimeKeys(t *testing.T) {
+	tree := NewFunc[time.Time, int](3, time.Time.Compare)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 10; i > 0; i-- {
+		tree.Insert(base.Add(time.Duration(i)*time.Hour), i)
+	}
+	checkInvariants(t, tree)
+
+	// Same instant in a different location compares equal
+	val, found := tree.Search(base.Add(3 * time.Hour).In(time.FixedZone("X", 3600)))
+	if !found || val != 3 {
+		t.Errorf("expected 3, got found=%v, val=%v", found, val)
+	}
+}
+
+func TestBTree_NewFunc_TupleKeys(t *testing.T) {
+	type pair struct {
+		tenant string
+		id     int
+	}
+	tree := NewFunc[pair, string](3, func(a, b pair) int {
+		return cmp.Or(cmp.Compare(a.tenant, b.tenant), cmp.Compare(a.id, b.id))
+	})
+
+	tree.Insert(pair{"b", 1}, "b1")
+	tree.Insert(pair{"a", 2}, "a2")
+	tree.Insert(pair{"a", 1}, "a1")
+	tree.Insert(pair{"b", 0}, "b0")
+	checkInvariants(t, tree)
+
+	for _, p := range []pair{{"a", 1}, {"a", 2}, {"b", 0}, {"b", 1}} {
+		val, found := tree.Search(p)
+		if !found || val != p.tenant+string(rune('0'+p.id)) {
+			t.Errorf("key %v: found=%v, val=%v", p, found, val)
+		}
+	}
+
+	if _, found := tree.Delete(pair{"a", 2}); !found {
+		t.Error("expected to delete {a 2}")
+	}
+	if _, found := tree.Search(pair{"a", 2}); found {
+		t.Error("expected {a 2} to be gone")
+	}
+}
+
+func TestBTree_Insert_OddOrderKeepsMinimum(t *testing.T) {
+	tree := New[int, int](3)
+
+	// Splitting [20, 30] before inserting 10 used to leave an empty right sibling
+	for _, k := range []int{20, 30, 10} {
+		tree.Insert(k, k)
+	}
+	checkInvariants(t, tree)
+}
+
+func TestBTree_Delete_Leaf(t *testing.T) {
+	tree := New[int, int](5)
+	for _, k := range []int{10, 20, 30} {
+		tree.Insert(k, k*10)
+	}
+
+	val, found := tree.Delete(20)
+	if !found || val != 200 {
+		t.Fatalf("expected to delete key 20 with value 200, got found=%v, val=%v", found, val)
+	}
+	if _, found := tree.Search(20); found {
+		t.Error("expected key 20 to be gone")
+	}
+	for _, k := range []int{10, 30} {
+		if _, found := tree.Search(k); !found {
+			t.Errorf("expected to find key %d", k)
+		}
+	}
+}
+
+func TestBTree_Delete_NotFound(t *testing.T) {
+	tree := New[int, string](3)
+
+	if _, found := tree.Delete(10); found {
+		t.Error("expected delete on empty tree to report not found")
+	}
+
+	tree.Insert(10, "ten")
+	if _, found := tree.Delete(99); found {
+		t.Error("expected not to delete key 99")
+	}
+	if _, found := tree.Search(10); !found {
+		t.Error("expected key 10 to remain")
+	}
+}
+
+func TestBTree_Delete_LastKey(t *testing.T) {
+	tree := New[int, string](3)
+	tree.Insert(10, "ten")
+
+	if _, found := tree.Delete(10); !found {
+		t.Fatal("expected to delete key 10")
+	}
+	if tree.root != nil {
+		t.Error("expected root to be nil after deleting the last key")
+	}
+
+	// Tree is usable again after becoming empty
+	tree.Insert(20, "twenty")
+	if val, found := tree.Search(20); !found || val != "twenty" {
+		t.Errorf("key 20: found=%v, val=%v", found, val)
+	}
+}
+
+func TestBTree_Delete_InternalKey(t *testing.T) {
+	tree := New[int, int](3)
+	for _, k := range []int{10, 20, 30, 40, 50, 60, 70} {
+		tree.Insert(k, k)
+	}
+
+	// 40 is the root key; it gets replaced by its predecessor
+	if _, found := tree.Delete(40); !found {
+		t.Fatal("expected to delete key 40")
+	}
+	checkInvariants(t, tree)
+
+	for _, k := range []int{10, 20, 30, 50, 60, 70} {
+		if _, found := tree.Search(k); !found {
+			t.Errorf("expected to find key %d", k)
+		}
+	}
+}
+
+func TestBTree_Delete_BorrowFromSibling(t *testing.T) {
+	tree := New[int, int](3)
+	for _, k := range []int{10, 20, 30, 5} {
+		tree.Insert(k, k)
+	}
+	//      [20]
+	//     /    \
+	//  [5,10]  [30]
+
+	// Removing 30 underflows the right child; it borrows through the separator
+	tree.Delete(30)
+	checkInvariants(t, tree)
+
+	//    [10]
+	//   /    \
+	// [5]    [20]
+	if len(tree.root.entries) != 1 || tree.root.entries[0].key != 10 {
+		t.Fatalf("expected root [10], got %v", tree.root.entries)
+	}
+	if tree.root.children[1].entries[0].key != 20 {
+		t.Errorf("expected right child [20], got %v", tree.root.children[1].entries)
+	}
+}
+
+func TestBTree_Delete_MergeShrinksRoot(t *testing.T) {
+	tree := New[int, int](3)
+	for _, k := range []int{10, 20, 30} {
+		tree.Insert(k, k)
+	}
+	//    [20]
+	//   /    \
+	// [10]   [30]
+
+	// Neither sibling can lend, so children merge and the root disappears
+	tree.Delete(10)
+	checkInvariants(t, tree)
+
+	if len(tree.root.children) != 0 {
+		t.Fatalf("expected root to be a leaf after merge, got %d children", len(tree.root.children))
+	}
+	if len(tree.root.entries) != 2 {
+		t.Errorf("expected root to have 2 entries, got %d", len(tree.root.entries))
+	}
+}
+
+func TestBTree_Delete_All(t *testing.T) {
+	tree := New[int, int](4)
+	for i := 0; i < 100; i++ {
+		tree.Insert(i, i)
+	}
+
+	for i := 0; i < 100; i++ {
+		val, found := tree.Delete(i)
+		if !found || val != i {
+			t.Fatalf("key %d: found=%v, val=%v", i, found, val)
+		}
+		checkInvariants(t, tree)
+	}
+	if tree.root != nil {
+		t.Error("expected empty tree")
+	}
+}
+
+func TestBTree_RandomInsertDelete(t *testing.T) {
+	for _, order := range []int{3, 4, 5, 6, 7, 16} {
+		rng := rand.New(rand.NewPCG(uint64(order), 42))
+		tree := New[int, int](order)
+		expected := make(map[int]int)
+
+		for i := 0; i < 5000; i++ {
+			k := rng.IntN(500)
+			if rng.IntN(3) == 0 {
+				val, found := tree.Delete(k)
+				want, ok := expected[k]
+				if found != ok || (found && val != want) {
+					t.Fatalf("order %d: Delete(%d) = %v, %v; want %v, %v", order, k, val, found, want, ok)
+				}
+				delete(expected, k)
+			} else if _, ok := expected[k]; !ok {
+				tree.Insert(k, i)
+				expected[k] = i
+			}
+			checkInvariants(t, tree)
+		}
+
+		for k, want := range expected {
+			if val, found := tree.Search(k); !found || val != want {
+				t.Fatalf("order %d: key %d: found=%v, val=%v, want %v", order, k, found, val, want)
+			}
+		}
+	}
+}
+
+// checkInvariants fails the test if the tree is not well-formed.
+func checkInvariants[K, V any](t *testing.T, tree *BTree[K, V]) {
+	t.Helper()
+	if err := tree.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
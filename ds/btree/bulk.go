@@ -0,0 +1,108 @@
+package btree
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+)
+
+// ErrUnsorted is returned by BulkLoad when its input is not in ascending
+// key order.
+var ErrUnsorted = errors.New("btree: bulk load input is not sorted")
+
+// BulkLoad fills an empty tree from seq, which must yield keys in
+// ascending order, building it bottom-up in O(n) instead of inserting key
+// by key. fillFactor in (0, 1] sets how full each node is made: 1 packs
+// nodes completely, which suits read-only data, while lower values leave
+// room for later inserts before nodes split. Nodes never drop below the
+// minimum occupancy whatever the fill factor.
+//
+// A repeated key behaves as it would with Insert: its value replaces the
+// earlier one, or is appended to it in a multimap. If seq is out of order
+// BulkLoad returns an error wrapping ErrUnsorted and leaves the tree empty.
+func (b *BTree[K, V]) BulkLoad(seq iter.Seq2[K, V], fillFactor float64) error {
+	if b.root != nil {
+		return errors.New("btree: bulk load into a non-empty tree")
+	}
+	if !(fillFactor > 0 && fillFactor <= 1) {
+		return fmt.Errorf("btree: fill factor %v out of range (0, 1]", fillFactor)
+	}
+
+	var entries []Entry[K, V]
+	for key, value := range seq {
+		if n := len(entries); n > 0 {
+			c := b.compare(entries[n-1].key, key)
+			if c > 0 {
+				return fmt.Errorf("%w: key %v follows %v", ErrUnsorted, key, entries[n-1].key)
+			}
+			if c == 0 {
+				if b.multi {
+					entries[n-1].dups = append(entries[n-1].dups, value)
+				} else {
+					entries[n-1].value = value
+				}
+				continue
+			}
+		}
+		entries = append(entries, Entry[K, V]{key: key, value: value})
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	maxEntries := b.order - 1
+	target := int(math.Round(fillFactor * float64(maxEntries)))
+	target = min(max(target, b.minEntries(), 1), maxEntries)
+
+	// Build one level at a time; each level's separators and nodes become
+	// the entries and children of the level above
+	var nodes []*Node[K, V]
+	for {
+		entries, nodes = b.buildLevel(entries, nodes, target)
+		if len(nodes) == 1 {
+			b.root = nodes[0]
+			return nil
+		}
+	}
+}
+
+func (b *BTree[K, V]) buildLevel(entries []Entry[K, V], children []*Node[K, V], target int) ([]Entry[K, V], []*Node[K, V]) {
+	// Split entries into count nodes of about target entries, with one
+	// separator between each pair. children is nil for the leaf level,
+	// otherwise it has one more element than entries.
+	count := (len(entries) + target + 1) / (target + 1)
+	for count > 1 && len(entries)-(count-1) < count*b.minEntries() {
+		// Too few entries to give every node its minimum
+		count--
+	}
+	count = max(count, 1)
+
+	per, extra := (len(entries)-(count-1))/count, (len(entries)-(count-1))%count
+	nodes := make([]*Node[K, V], 0, count)
+	separators := make([]Entry[K, V], 0, count-1)
+	for i := 0; i < count; i++ {
+		size := per
+		if i < extra {
+			size++
+		}
+		node := b.newNode()
+		node.entries = append(node.entries, entries[:size]...)
+		node.count = size
+		if children != nil {
+			node.children = append(node.children, children[:size+1]...)
+			for _, child := range node.children {
+				node.count += child.count
+			}
+			children = children[size+1:]
+		}
+		nodes = append(nodes, node)
+
+		entries = entries[size:]
+		if i < count-1 {
+			separators = append(separators, entries[0])
+			entries = entries[1:]
+		}
+	}
+	return separators, nodes
+}
@@ -0,0 +1,146 @@
+package btree
+
+import (
+	"errors"
+	"iter"
+	"slices"
+	"testing"
+)
+
+func sortedSeq(n int) iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		for k := 0; k < n; k++ {
+			if !yield(k, k*10) {
+				return
+			}
+		}
+	}
+}
+
+func TestBulkLoad_AllSizesAndOrders(t *testing.T) {
+	for _, order := range []int{3, 4, 5, 6, 7, 16} {
+		for _, fill := range []float64{0.01, 0.5, 0.69, 1} {
+			for n := 0; n <= 300; n++ {
+				tree := New[int, int](order)
+				if err := tree.BulkLoad(sortedSeq(n), fill); err != nil {
+					t.Fatalf("order %d, fill %v, n %d: %v", order, fill, n, err)
+				}
+				checkInvariants(t, tree)
+
+				keys := collectKeys(tree.All())
+				if len(keys) != n {
+					t.Fatalf("order %d, fill %v, n %d: got %d keys", order, fill, n, len(keys))
+				}
+				for i, k := range keys {
+					if k != i {
+						t.Fatalf("order %d, fill %v, n %d: key %d at position %d", order, fill, n, k, i)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBulkLoad_FillFactor(t *testing.T) {
+	leafFill := func(tree *BTree[int, int]) (leaves, entries int) {
+		var walk func(node *Node[int, int])
+		walk = func(node *Node[int, int]) {
+			if len(node.children) == 0 {
+				leaves++
+				entries += len(node.entries)
+			}
+			for _, child := range node.children {
+				walk(child)
+			}
+		}
+		walk(tree.root)
+		return leaves, entries
+	}
+
+	full := New[int, int](11)
+	full.BulkLoad(sortedSeq(10000), 1)
+	leaves, entries := leafFill(full)
+	if avg := float64(entries) / float64(leaves); avg < 9.9 {
+		t.Errorf("fill 1: expected full leaves, got %.2f entries per leaf", avg)
+	}
+
+	half := New[int, int](11)
+	half.BulkLoad(sortedSeq(10000), 0.5)
+	leaves, entries = leafFill(half)
+	if avg := float64(entries) / float64(leaves); avg < 4.9 || avg > 5.1 {
+		t.Errorf("fill 0.5: expected about 5 entries per leaf, got %.2f", avg)
+	}
+
+	// The tree keeps working normally afterwards
+	for k := 10000; k < 11000; k++ {
+		half.Insert(k, k*10)
+	}
+	for k := 0; k < 11000; k += 3 {
+		half.Delete(k)
+	}
+	checkInvariants(t, half)
+	if val, found := half.Search(10002); found {
+		t.Errorf("expected 10002 to be deleted, got %d", val)
+	}
+	if val, found := half.Search(10001); !found || val != 100010 {
+		t.Errorf("key 10001: found=%v, val=%v", found, val)
+	}
+}
+
+func TestBulkLoad_Unsorted(t *testing.T) {
+	tree := New[int, int](4)
+	seq := func(yield func(int, int) bool) {
+		for _, k := range []int{1, 2, 5, 3} {
+			if !yield(k, k) {
+				return
+			}
+		}
+	}
+	if err := tree.BulkLoad(seq, 1); !errors.Is(err, ErrUnsorted) {
+		t.Fatalf("expected ErrUnsorted, got %v", err)
+	}
+	if tree.root != nil {
+		t.Error("expected tree to stay empty after a failed load")
+	}
+}
+
+func TestBulkLoad_Errors(t *testing.T) {
+	tree := New[int, int](4)
+	for _, fill := range []float64{0, -0.5, 1.5} {
+		if err := tree.BulkLoad(sortedSeq(10), fill); err == nil {
+			t.Errorf("expected error for fill factor %v", fill)
+		}
+	}
+
+	tree.Insert(1, 1)
+	if err := tree.BulkLoad(sortedSeq(10), 1); err == nil {
+		t.Error("expected error loading into a non-empty tree")
+	}
+}
+
+func TestBulkLoad_DuplicateKeys(t *testing.T) {
+	seq := func(yield func(string, int) bool) {
+		for i, k := range []string{"a", "b", "b", "c", "c", "c"} {
+			if !yield(k, i) {
+				return
+			}
+		}
+	}
+
+	tree := New[string, int](3)
+	if err := tree.BulkLoad(seq, 1); err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := tree.Search("c"); val != 5 {
+		t.Errorf("expected last value 5 for \"c\", got %d", val)
+	}
+
+	multi := New[string, int](3, WithMultimap())
+	if err := multi.BulkLoad(seq, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := multi.SearchAll("c"); !slices.Equal(got, []int{3, 4, 5}) {
+		t.Errorf("expected [3 4 5] for \"c\", got %v", got)
+	}
+	checkInvariants(t, multi)
+}
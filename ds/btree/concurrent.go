@@ -0,0 +1,440 @@
+package btree
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// ConcurrentBTree is a B-tree that is safe for concurrent use. Every node
+// has its own read/write latch and operations descend by latch crabbing:
+// a child is latched before its parent is released, so operations in
+// different subtrees run in parallel.
+//
+// Readers hold read latches and release each parent as soon as the child
+// is latched. Writers first try the same descent, write-latching only the
+// leaf; most changes fit in the leaf and finish there. Otherwise the writer
+// starts again with write latches, keeping the ancestors its change might
+// propagate into and releasing them as soon as it reaches a node that
+// cannot split (Insert) or underflow (Delete).
+type ConcurrentBTree[K, V any] struct {
+	// mu guards root and height: it is the latch above the root node
+	mu      sync.RWMutex
+	root    *concurrentNode[K, V]
+	height  int
+	order   int
+	compare func(a, b K) int
+	size    atomic.Int64
+}
+
+type concurrentNode[K, V any] struct {
+	latch    sync.RWMutex
+	entries  []Entry[K, V]
+	children []*concurrentNode[K, V]
+}
+
+// NewConcurrent returns an empty concurrent tree of the given order whose
+// keys are ordered by their natural ordering.
+func NewConcurrent[K cmp.Ordered, V any](order int) *ConcurrentBTree[K, V] {
+	return NewConcurrentFunc[K, V](order, cmp.Compare[K])
+}
+
+// NewConcurrentFunc returns an empty concurrent tree of the given order
+// whose keys are ordered by compare.
+func NewConcurrentFunc[K, V any](order int, compare func(a, b K) int) *ConcurrentBTree[K, V] {
+	return &ConcurrentBTree[K, V]{
+		order:   order,
+		compare: compare,
+	}
+}
+
+func (c *ConcurrentBTree[K, V]) Len() int {
+	return int(c.size.Load())
+}
+
+func (c *ConcurrentBTree[K, V]) Search(key K) (V, bool) {
+	var zero V
+
+	c.mu.RLock()
+	node := c.root
+	if node == nil {
+		c.mu.RUnlock()
+		return zero, false
+	}
+	node.latch.RLock()
+	c.mu.RUnlock()
+
+	for {
+		index, found := c.searchEntries(key, node.entries)
+		if found {
+			value := node.entries[index].value
+			node.latch.RUnlock()
+			return value, true
+		}
+		if len(node.children) == 0 {
+			node.latch.RUnlock()
+			return zero, false
+		}
+
+		child := node.children[index]
+		child.latch.RLock()
+		node.latch.RUnlock()
+		node = child
+	}
+}
+
+func (c *ConcurrentBTree[K, V]) lockLeaf(key K) *concurrentNode[K, V] {
+	// Read-crab down to the leaf where key belongs and return it write
+	// latched. Returns nil if the tree is empty or key sits in an internal
+	// node, which both need the pessimistic path.
+	c.mu.RLock()
+	node, height := c.root, c.height
+	if node == nil {
+		c.mu.RUnlock()
+		return nil
+	}
+	if height == 1 {
+		node.latch.Lock()
+		c.mu.RUnlock()
+		return node
+	}
+	node.latch.RLock()
+	c.mu.RUnlock()
+
+	for depth := 1; ; depth++ {
+		index, found := c.searchEntries(key, node.entries)
+		if found {
+			node.latch.RUnlock()
+			return nil
+		}
+		child := node.children[index]
+		if depth+1 == height {
+			child.latch.Lock()
+			node.latch.RUnlock()
+			return child
+		}
+		child.latch.RLock()
+		node.latch.RUnlock()
+		node = child
+	}
+}
+
+// crab is the set of latches a writer currently holds.
+type crab[K, V any] struct {
+	tree     *ConcurrentBTree[K, V]
+	deleting bool
+	// rootLocked reports whether tree.mu is held
+	rootLocked bool
+	locked     []*concurrentNode[K, V]
+	// path holds the latched ancestors a change may still propagate into,
+	// with the index of the child taken from each
+	path []crabStep[K, V]
+	// pinned stays latched even when its ancestors are released
+	pinned *concurrentNode[K, V]
+}
+
+type crabStep[K, V any] struct {
+	node  *concurrentNode[K, V]
+	index int
+}
+
+func (cr *crab[K, V]) descend(node *concurrentNode[K, V], index int) *concurrentNode[K, V] {
+	// Latch node's child at index; if the child is safe nothing above it
+	// can change, so every latch but the pinned one is released
+	child := node.children[index]
+	child.latch.Lock()
+	cr.path = append(cr.path, crabStep[K, V]{node, index})
+	if cr.tree.isSafe(child, false, cr.deleting) {
+		cr.releaseAncestors()
+	}
+	cr.locked = append(cr.locked, child)
+	return child
+}
+
+func (cr *crab[K, V]) releaseAncestors() {
+	for _, n := range cr.locked {
+		if n != cr.pinned {
+			n.latch.Unlock()
+		}
+	}
+	cr.locked = cr.locked[:0]
+	if cr.pinned != nil {
+		cr.locked = append(cr.locked, cr.pinned)
+	}
+	cr.path = cr.path[:0]
+	if cr.rootLocked {
+		cr.tree.mu.Unlock()
+		cr.rootLocked = false
+	}
+}
+
+func (cr *crab[K, V]) releaseAll() {
+	cr.pinned = nil
+	cr.releaseAncestors()
+}
+
+func (c *ConcurrentBTree[K, V]) isSafe(node *concurrentNode[K, V], root, deleting bool) bool {
+	// A node is safe if the pending change cannot propagate above it
+	if !deleting {
+		return len(node.entries) < c.order-1
+	}
+	if root {
+		return len(node.entries) > 1
+	}
+	return len(node.entries) > c.minEntries()
+}
+
+func (c *ConcurrentBTree[K, V]) lockRoot(deleting bool) (*crab[K, V], *concurrentNode[K, V]) {
+	// Returns with tree.mu and the root latched, or just tree.mu if the
+	// tree is empty. tree.mu is dropped straight away if the root is safe.
+	c.mu.Lock()
+	cr := &crab[K, V]{tree: c, deleting: deleting, rootLocked: true}
+	root := c.root
+	if root != nil {
+		root.latch.Lock()
+		cr.locked = append(cr.locked, root)
+		if c.isSafe(root, true, deleting) {
+			c.mu.Unlock()
+			cr.rootLocked = false
+		}
+	}
+	return cr, root
+}
+
+// Insert stores value under key. If key is already present its value is
+// replaced and the previous value is returned with true.
+func (c *ConcurrentBTree[K, V]) Insert(key K, value V) (V, bool) {
+	var zero V
+	if leaf := c.lockLeaf(key); leaf != nil {
+		index, found := c.searchEntries(key, leaf.entries)
+		if found {
+			old := leaf.entries[index].value
+			leaf.entries[index].value = value
+			leaf.latch.Unlock()
+			return old, true
+		}
+		if c.isSafe(leaf, false, false) {
+			leaf.entries = slices.Insert(leaf.entries, index, Entry[K, V]{key: key, value: value})
+			c.size.Add(1)
+			leaf.latch.Unlock()
+			return zero, false
+		}
+		leaf.latch.Unlock()
+	}
+
+	cr, node := c.lockRoot(false)
+	defer cr.releaseAll()
+
+	entry := Entry[K, V]{key: key, value: value}
+	if node == nil {
+		c.root = c.newNode()
+		c.root.entries = append(c.root.entries, entry)
+		c.height = 1
+		c.size.Add(1)
+		return zero, false
+	}
+
+	var index int
+	for {
+		var found bool
+		index, found = c.searchEntries(key, node.entries)
+		if found {
+			old := node.entries[index].value
+			node.entries[index].value = value
+			return old, true
+		}
+		if len(node.children) == 0 {
+			break
+		}
+		node = cr.descend(node, index)
+	}
+
+	node.entries = slices.Insert(node.entries, index, entry)
+	c.size.Add(1)
+
+	// Propagate splits up through the latched ancestors
+	promoted, rightSib, split := c.splitIfFull(node)
+	for i := len(cr.path) - 1; i >= 0 && split; i-- {
+		parent, index := cr.path[i].node, cr.path[i].index
+		parent.entries = slices.Insert(parent.entries, index, promoted)
+		parent.children = slices.Insert(parent.children, index+1, rightSib)
+		promoted, rightSib, split = c.splitIfFull(parent)
+	}
+	if split {
+		// Root split; the root was never safe so tree.mu is still held
+		newRoot := c.newNode()
+		newRoot.entries = append(newRoot.entries, promoted)
+		newRoot.children = append(newRoot.children, c.root, rightSib)
+		c.root = newRoot
+		c.height++
+	}
+	return zero, false
+}
+
+// Delete removes key and returns its value.
+func (c *ConcurrentBTree[K, V]) Delete(key K) (V, bool) {
+	var zero V
+	if leaf := c.lockLeaf(key); leaf != nil {
+		index, found := c.searchEntries(key, leaf.entries)
+		if !found {
+			leaf.latch.Unlock()
+			return zero, false
+		}
+		// The root leaf may go down to one entry, any other leaf to minEntries
+		if len(leaf.entries) > max(c.minEntries(), 1) {
+			value := leaf.entries[index].value
+			leaf.entries = slices.Delete(leaf.entries, index, index+1)
+			c.size.Add(-1)
+			leaf.latch.Unlock()
+			return value, true
+		}
+		leaf.latch.Unlock()
+	}
+
+	cr, node := c.lockRoot(true)
+	defer cr.releaseAll()
+
+	if node == nil {
+		return zero, false
+	}
+
+	// Find the key. If it is in an internal node, that node stays latched
+	// (pinned) while we continue down to its in-order predecessor.
+	var value V
+	for {
+		index, found := c.searchEntries(key, node.entries)
+		leaf := len(node.children) == 0
+		if found && leaf {
+			value = node.entries[index].value
+			node.entries = slices.Delete(node.entries, index, index+1)
+			break
+		}
+		if leaf {
+			return zero, false
+		}
+		if found {
+			value = node.entries[index].value
+			cr.pinned = node
+			node = cr.descend(node, index)
+			for len(node.children) > 0 {
+				node = cr.descend(node, len(node.children)-1)
+			}
+			last := len(node.entries) - 1
+			cr.pinned.entries[index] = node.entries[last]
+			node.entries = slices.Delete(node.entries, last, last+1)
+			break
+		}
+		node = cr.descend(node, index)
+	}
+	c.size.Add(-1)
+
+	// Rebalance up through the latched ancestors
+	for i := len(cr.path) - 1; i >= 0; i-- {
+		c.rebalance(cr.path[i].node, cr.path[i].index)
+	}
+	if cr.rootLocked && len(c.root.entries) == 0 {
+		// Root emptied: make tree shorter
+		if len(c.root.children) == 0 {
+			c.root = nil
+		} else {
+			c.root = c.root.children[0]
+		}
+		c.height--
+	}
+	return value, true
+}
+
+func (c *ConcurrentBTree[K, V]) newNode() *concurrentNode[K, V] {
+	return &concurrentNode[K, V]{
+		entries:  make([]Entry[K, V], 0, c.order),
+		children: make([]*concurrentNode[K, V], 0, c.order+1),
+	}
+}
+
+func (c *ConcurrentBTree[K, V]) minEntries() int {
+	return (c.order+1)/2 - 1
+}
+
+func (c *ConcurrentBTree[K, V]) searchEntries(key K, entries []Entry[K, V]) (int, bool) {
+	return slices.BinarySearchFunc(entries, key, func(e Entry[K, V], key K) int {
+		return c.compare(e.key, key)
+	})
+}
+
+func (c *ConcurrentBTree[K, V]) splitIfFull(node *concurrentNode[K, V]) (Entry[K, V], *concurrentNode[K, V], bool) {
+	if len(node.entries) < c.order {
+		return Entry[K, V]{}, nil, false
+	}
+	mid := len(node.entries) / 2
+
+	// The new sibling is unreachable until the (latched) parent links it
+	rightSib := c.newNode()
+	promoted := node.entries[mid]
+	rightSib.entries = append(rightSib.entries, node.entries[mid+1:]...)
+	clear(node.entries[mid:])
+	node.entries = node.entries[:mid]
+
+	if len(node.children) > 0 {
+		rightSib.children = append(rightSib.children, node.children[mid+1:]...)
+		clear(node.children[mid+1:])
+		node.children = node.children[:mid+1]
+	}
+	return promoted, rightSib, true
+}
+
+func (c *ConcurrentBTree[K, V]) rebalance(node *concurrentNode[K, V], index int) {
+	// node and node.children[index] are latched by the caller. Siblings are
+	// latched here, under node's latch, so no other writer can reach them
+	// from above.
+	child := node.children[index]
+	minEntries := c.minEntries()
+	if len(child.entries) >= minEntries {
+		return
+	}
+
+	if index > 0 {
+		leftSib := node.children[index-1]
+		leftSib.latch.Lock()
+		defer leftSib.latch.Unlock()
+		if len(leftSib.entries) > minEntries {
+			// Rotate right through the separator
+			last := len(leftSib.entries) - 1
+			child.entries = slices.Insert(child.entries, 0, node.entries[index-1])
+			node.entries[index-1] = leftSib.entries[last]
+			leftSib.entries = slices.Delete(leftSib.entries, last, last+1)
+			if len(leftSib.children) > 0 {
+				last = len(leftSib.children) - 1
+				child.children = slices.Insert(child.children, 0, leftSib.children[last])
+				leftSib.children = slices.Delete(leftSib.children, last, last+1)
+			}
+			return
+		}
+	}
+	if index < len(node.children)-1 {
+		rightSib := node.children[index+1]
+		rightSib.latch.Lock()
+		defer rightSib.latch.Unlock()
+		if len(rightSib.entries) > minEntries {
+			// Rotate left through the separator
+			child.entries = append(child.entries, node.entries[index])
+			node.entries[index] = rightSib.entries[0]
+			rightSib.entries = slices.Delete(rightSib.entries, 0, 1)
+			if len(rightSib.children) > 0 {
+				child.children = append(child.children, rightSib.children[0])
+				rightSib.children = slices.Delete(rightSib.children, 0, 1)
+			}
+			return
+		}
+	}
+
+	if index > 0 {
+		index--
+	}
+	leftSib, rightSib := node.children[index], node.children[index+1]
+	leftSib.entries = append(leftSib.entries, node.entries[index])
+	leftSib.entries = append(leftSib.entries, rightSib.entries...)
+	leftSib.children = append(leftSib.children, rightSib.children...)
+	node.entries = slices.Delete(node.entries, index, index+1)
+	node.children = slices.Delete(node.children, index+1, index+2)
+}
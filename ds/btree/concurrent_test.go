@@ -0,0 +1,222 @@
+package btree
+
+import (
+	"math/rand/v2"
+	"sync"
+	"testing"
+)
+
+// checkConcurrentInvariants verifies ordering, occupancy bounds, uniform
+// leaf depth and the size counter. It must not run alongside writers.
+func checkConcurrentInvariants[K, V any](t *testing.T, tree *ConcurrentBTree[K, V]) {
+	t.Helper()
+	if tree.root == nil {
+		if tree.Len() != 0 {
+			t.Fatalf("empty tree reports Len %d", tree.Len())
+		}
+		return
+	}
+
+	leafDepth, count := -1, 0
+	var walk func(node *concurrentNode[K, V], depth int, lo, hi *K)
+	walk = func(node *concurrentNode[K, V], depth int, lo, hi *K) {
+		if node != tree.root && len(node.entries) < tree.minEntries() {
+			t.Fatalf("node %v has %d entries, below minimum %d", node.entries, len(node.entries), tree.minEntries())
+		}
+		if len(node.entries) > tree.order-1 || len(node.entries) == 0 {
+			t.Fatalf("node %v has %d entries, outside [1, %d]", node.entries, len(node.entries), tree.order-1)
+		}
+		for i, e := range node.entries {
+			if (i > 0 && tree.compare(node.entries[i-1].key, e.key) >= 0) ||
+				(lo != nil && tree.compare(e.key, *lo) <= 0) ||
+				(hi != nil && tree.compare(e.key, *hi) >= 0) {
+				t.Fatalf("node %v is out of order", node.entries)
+			}
+		}
+		count += len(node.entries)
+
+		if len(node.children) == 0 {
+			if leafDepth < 0 {
+				leafDepth = depth
+			} else if depth != leafDepth {
+				t.Fatalf("leaf at depth %d, expected %d", depth, leafDepth)
+			}
+			return
+		}
+		if len(node.children) != len(node.entries)+1 {
+			t.Fatalf("node %v has %d children, expected %d", node.entries, len(node.children), len(node.entries)+1)
+		}
+		for i, child := range node.children {
+			childLo, childHi := lo, hi
+			if i > 0 {
+				childLo = &node.entries[i-1].key
+			}
+			if i < len(node.entries) {
+				childHi = &node.entries[i].key
+			}
+			walk(child, depth+1, childLo, childHi)
+		}
+	}
+	walk(tree.root, 0, nil, nil)
+
+	if count != tree.Len() {
+		t.Fatalf("tree holds %d keys but Len is %d", count, tree.Len())
+	}
+}
+
+func TestConcurrentBTree_InsertSearchDelete(t *testing.T) {
+	tree := NewConcurrent[int, string](3)
+
+	if _, replaced := tree.Insert(10, "ten"); replaced {
+		t.Error("expected fresh insert")
+	}
+	tree.Insert(20, "twenty")
+	tree.Insert(5, "five")
+	if old, replaced := tree.Insert(10, "TEN"); !replaced || old != "ten" {
+		t.Errorf("expected to replace \"ten\", got %q, %v", old, replaced)
+	}
+	if val, found := tree.Search(10); !found || val != "TEN" {
+		t.Errorf("key 10: found=%v, val=%q", found, val)
+	}
+	if _, found := tree.Search(99); found {
+		t.Error("expected not to find key 99")
+	}
+
+	if val, found := tree.Delete(5); !found || val != "five" {
+		t.Errorf("Delete(5): found=%v, val=%q", found, val)
+	}
+	if _, found := tree.Delete(5); found {
+		t.Error("expected second Delete(5) to miss")
+	}
+	if tree.Len() != 2 {
+		t.Errorf("expected Len 2, got %d", tree.Len())
+	}
+	checkConcurrentInvariants(t, tree)
+}
+
+func TestConcurrentBTree_RandomInsertDelete(t *testing.T) {
+	for _, order := range []int{3, 4, 5, 6, 7, 16} {
+		rng := rand.New(rand.NewPCG(uint64(order), 1))
+		tree := NewConcurrent[int, int](order)
+		expected := make(map[int]int)
+
+		for i := 0; i < 5000; i++ {
+			k := rng.IntN(500)
+			if rng.IntN(3) == 0 {
+				_, found := tree.Delete(k)
+				if _, ok := expected[k]; found != ok {
+					t.Fatalf("order %d: Delete(%d) = %v, want %v", order, k, found, ok)
+				}
+				delete(expected, k)
+			} else {
+				tree.Insert(k, i)
+				expected[k] = i
+			}
+		}
+		checkConcurrentInvariants(t, tree)
+
+		for k := 0; k < 500; k++ {
+			val, found := tree.Search(k)
+			want, ok := expected[k]
+			if found != ok || val != want {
+				t.Fatalf("order %d: key %d: found=%v, val=%v; want %v, %v", order, k, found, val, ok, want)
+			}
+		}
+
+		// Delete everything so the root collapses back to nil
+		for k := range expected {
+			tree.Delete(k)
+		}
+		if tree.root != nil || tree.Len() != 0 {
+			t.Fatalf("order %d: expected empty tree, Len is %d", order, tree.Len())
+		}
+	}
+}
+
+func TestConcurrentBTree_ParallelWriters(t *testing.T) {
+	tree := NewConcurrent[int, int](4)
+	const workers, perWorker = 8, 2000
+
+	// Each worker owns the keys congruent to its id, so the final contents
+	// are deterministic even though the operations interleave
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(uint64(w), 2))
+			for i := 0; i < perWorker; i++ {
+				k := i*workers + w
+				tree.Insert(k, k)
+				if rng.IntN(2) == 0 {
+					if _, found := tree.Delete(k); !found {
+						t.Errorf("worker %d lost its own key %d", w, k)
+						return
+					}
+				} else if val, found := tree.Search(k); !found || val != k {
+					t.Errorf("worker %d: key %d: found=%v, val=%v", w, k, found, val)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	checkConcurrentInvariants(t, tree)
+
+	for k := 0; k < workers*perWorker; k++ {
+		if val, found := tree.Search(k); found && val != k {
+			t.Fatalf("key %d has value %d", k, val)
+		}
+	}
+}
+
+func TestConcurrentBTree_ReadersAndWriters(t *testing.T) {
+	tree := NewConcurrent[int, int](5)
+	// Even keys are stable; writers only touch odd keys
+	for k := 0; k < 4000; k += 2 {
+		tree.Insert(k, k)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(uint64(r), 3))
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				k := rng.IntN(2000) * 2
+				if val, found := tree.Search(k); !found || val != k {
+					t.Errorf("stable key %d: found=%v, val=%v", k, found, val)
+					return
+				}
+			}
+		}()
+	}
+
+	var writers sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			rng := rand.New(rand.NewPCG(uint64(w), 4))
+			for i := 0; i < 5000; i++ {
+				k := rng.IntN(2000)*2 + 1
+				if rng.IntN(2) == 0 {
+					tree.Delete(k)
+				} else {
+					tree.Insert(k, k)
+				}
+			}
+		}()
+	}
+	writers.Wait()
+	close(stop)
+	wg.Wait()
+	checkConcurrentInvariants(t, tree)
+}
@@ -0,0 +1,194 @@
+package btree
+
+import "iter"
+
+// All returns an iterator over every key/value pair in ascending key order.
+// In multimap mode a key is yielded once per value, in insertion order.
+func (b *BTree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if b.root == nil {
+			return
+		}
+		var zero K
+		b.ascend(b.root, zero, false, yield)
+	}
+}
+
+// Ascend returns an iterator over the pairs with key >= from, in ascending key order.
+func (b *BTree[K, V]) Ascend(from K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if b.root == nil {
+			return
+		}
+		b.ascend(b.root, from, true, yield)
+	}
+}
+
+// Descend returns an iterator over the pairs with key <= from, in descending key order.
+func (b *BTree[K, V]) Descend(from K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if b.root == nil {
+			return
+		}
+		b.descend(b.root, from, true, yield)
+	}
+}
+
+// Range returns an iterator over the pairs with lo <= key < hi, in ascending key order.
+func (b *BTree[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range b.Ascend(lo) {
+			if b.compare(k, hi) >= 0 || !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Min returns the smallest key and its value.
+func (b *BTree[K, V]) Min() (K, V, bool) {
+	if b.root == nil {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+
+	node := b.root
+	for len(node.children) > 0 {
+		node = node.children[0]
+	}
+	entry := node.entries[0]
+	return entry.key, entry.value, true
+}
+
+// Max returns the largest key and its value.
+func (b *BTree[K, V]) Max() (K, V, bool) {
+	if b.root == nil {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+
+	node := b.root
+	for len(node.children) > 0 {
+		node = node.children[len(node.children)-1]
+	}
+	entry := node.entries[len(node.entries)-1]
+	return entry.key, entry.value, true
+}
+
+// Floor returns the largest key <= key and its value.
+func (b *BTree[K, V]) Floor(key K) (K, V, bool) {
+	var best *Entry[K, V]
+	for node := b.root; node != nil; {
+		index, found := b.searchEntries(key, node.entries)
+		if found {
+			return node.entries[index].key, node.entries[index].value, true
+		}
+		if index > 0 {
+			best = &node.entries[index-1]
+		}
+		if len(node.children) == 0 {
+			break
+		}
+		node = node.children[index]
+	}
+
+	if best == nil {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+	return best.key, best.value, true
+}
+
+// Ceiling returns the smallest key >= key and its value.
+func (b *BTree[K, V]) Ceiling(key K) (K, V, bool) {
+	var best *Entry[K, V]
+	for node := b.root; node != nil; {
+		index, found := b.searchEntries(key, node.entries)
+		if found {
+			return node.entries[index].key, node.entries[index].value, true
+		}
+		if index < len(node.entries) {
+			best = &node.entries[index]
+		}
+		if len(node.children) == 0 {
+			break
+		}
+		node = node.children[index]
+	}
+
+	if best == nil {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+	return best.key, best.value, true
+}
+
+func (b *BTree[K, V]) ascend(node *Node[K, V], from K, bounded bool, yield func(K, V) bool) bool {
+	// In-order walk of node's subtree. When bounded, entries (and whole
+	// subtrees) below from are skipped. Returns false once yield stops.
+	start := 0
+	skipChild := false
+	if bounded {
+		// Everything in children[start] is < entries[start], so if that
+		// entry is from itself the child can be skipped entirely
+		start, skipChild = b.searchEntries(from, node.entries)
+	}
+	leaf := len(node.children) == 0
+
+	if !leaf && !skipChild && !b.ascend(node.children[start], from, bounded, yield) {
+		return false
+	}
+	for i := start; i < len(node.entries); i++ {
+		if !node.entries[i].yieldAll(yield) {
+			return false
+		}
+		if !leaf && !b.ascend(node.children[i+1], from, false, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *BTree[K, V]) descend(node *Node[K, V], from K, bounded bool, yield func(K, V) bool) bool {
+	// Reverse in-order walk of node's subtree, mirroring ascend
+	end := len(node.entries)
+	skipChild := false
+	if bounded {
+		index, found := b.searchEntries(from, node.entries)
+		end = index
+		if found {
+			// children[index+1] is entirely > from
+			end, skipChild = index+1, true
+		}
+	}
+	leaf := len(node.children) == 0
+
+	if !leaf && !skipChild && !b.descend(node.children[end], from, bounded, yield) {
+		return false
+	}
+	for i := end - 1; i >= 0; i-- {
+		if !node.entries[i].yieldAll(yield) {
+			return false
+		}
+		if !leaf && !b.descend(node.children[i], from, false, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Entry[K, V]) yieldAll(yield func(K, V) bool) bool {
+	if !yield(e.key, e.value) {
+		return false
+	}
+	for _, v := range e.dups {
+		if !yield(e.key, v) {
+			return false
+		}
+	}
+	return true
+}
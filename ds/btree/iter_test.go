@@ -0,0 +1,206 @@
+package btree
+
+import (
+	"slices"
+	"testing"
+)
+
+func buildTree(order int, keys ...int) *BTree[int, int] {
+	tree := New[int, int](order)
+	for _, k := range keys {
+		tree.Insert(k, k*10)
+	}
+	return tree
+}
+
+func collectKeys(seq func(yield func(int, int) bool)) []int {
+	var keys []int
+	for k, v := range seq {
+		if v != k*10 {
+			panic("value does not match key")
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestBTree_All(t *testing.T) {
+	tree := buildTree(3, 50, 30, 70, 20, 40, 60, 80, 10)
+
+	got := collectKeys(tree.All())
+	want := []int{10, 20, 30, 40, 50, 60, 70, 80}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestBTree_All_Empty(t *testing.T) {
+	tree := New[int, int](3)
+	if got := collectKeys(tree.All()); len(got) != 0 {
+		t.Errorf("expected no keys, got %v", got)
+	}
+}
+
+func TestBTree_All_EarlyStop(t *testing.T) {
+	tree := buildTree(3, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+
+	var got []int
+	for k := range tree.All() {
+		if k > 4 {
+			break
+		}
+		got = append(got, k)
+	}
+	if !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("expected [1 2 3 4], got %v", got)
+	}
+}
+
+func TestBTree_Ascend(t *testing.T) {
+	tree := buildTree(4)
+	for i := 0; i < 100; i += 2 {
+		tree.Insert(i, i*10)
+	}
+
+	// Present key
+	got := collectKeys(tree.Ascend(90))
+	if !slices.Equal(got, []int{90, 92, 94, 96, 98}) {
+		t.Errorf("Ascend(90): got %v", got)
+	}
+
+	// Absent key starts at the next larger one
+	got = collectKeys(tree.Ascend(91))
+	if !slices.Equal(got, []int{92, 94, 96, 98}) {
+		t.Errorf("Ascend(91): got %v", got)
+	}
+
+	if got = collectKeys(tree.Ascend(100)); len(got) != 0 {
+		t.Errorf("Ascend(100): expected nothing, got %v", got)
+	}
+	if got = collectKeys(tree.Ascend(-5)); len(got) != 50 {
+		t.Errorf("Ascend(-5): expected 50 keys, got %d", len(got))
+	}
+}
+
+func TestBTree_Descend(t *testing.T) {
+	tree := buildTree(4)
+	for i := 0; i < 100; i += 2 {
+		tree.Insert(i, i*10)
+	}
+
+	got := collectKeys(tree.Descend(8))
+	if !slices.Equal(got, []int{8, 6, 4, 2, 0}) {
+		t.Errorf("Descend(8): got %v", got)
+	}
+
+	got = collectKeys(tree.Descend(9))
+	if !slices.Equal(got, []int{8, 6, 4, 2, 0}) {
+		t.Errorf("Descend(9): got %v", got)
+	}
+
+	if got = collectKeys(tree.Descend(-1)); len(got) != 0 {
+		t.Errorf("Descend(-1): expected nothing, got %v", got)
+	}
+
+	got = collectKeys(tree.Descend(1000))
+	if len(got) != 50 || got[0] != 98 || !slices.IsSortedFunc(got, func(a, b int) int { return b - a }) {
+		t.Errorf("Descend(1000): expected all keys descending, got %v", got)
+	}
+}
+
+func TestBTree_Range(t *testing.T) {
+	tree := buildTree(3)
+	for i := 0; i < 50; i++ {
+		tree.Insert(i, i*10)
+	}
+
+	got := collectKeys(tree.Range(10, 15))
+	if !slices.Equal(got, []int{10, 11, 12, 13, 14}) {
+		t.Errorf("Range(10, 15): got %v", got)
+	}
+
+	if got = collectKeys(tree.Range(20, 20)); len(got) != 0 {
+		t.Errorf("Range(20, 20): expected nothing, got %v", got)
+	}
+	if got = collectKeys(tree.Range(45, 100)); !slices.Equal(got, []int{45, 46, 47, 48, 49}) {
+		t.Errorf("Range(45, 100): got %v", got)
+	}
+}
+
+func TestBTree_MinMax(t *testing.T) {
+	tree := New[int, int](3)
+	if _, _, ok := tree.Min(); ok {
+		t.Error("expected Min on empty tree to fail")
+	}
+	if _, _, ok := tree.Max(); ok {
+		t.Error("expected Max on empty tree to fail")
+	}
+
+	for _, k := range []int{50, 30, 70, 20, 40, 60, 80} {
+		tree.Insert(k, k*10)
+	}
+	if k, v, ok := tree.Min(); !ok || k != 20 || v != 200 {
+		t.Errorf("Min: got %d, %d, %v", k, v, ok)
+	}
+	if k, v, ok := tree.Max(); !ok || k != 80 || v != 800 {
+		t.Errorf("Max: got %d, %d, %v", k, v, ok)
+	}
+}
+
+func TestBTree_FloorCeiling(t *testing.T) {
+	tree := buildTree(3)
+	for i := 10; i <= 100; i += 10 {
+		tree.Insert(i, i*10)
+	}
+
+	tests := []struct {
+		key             int
+		floor, ceil     int
+		floorOK, ceilOK bool
+	}{
+		{key: 5, ceil: 10, ceilOK: true},
+		{key: 10, floor: 10, ceil: 10, floorOK: true, ceilOK: true},
+		{key: 55, floor: 50, ceil: 60, floorOK: true, ceilOK: true},
+		{key: 100, floor: 100, ceil: 100, floorOK: true, ceilOK: true},
+		{key: 101, floor: 100, floorOK: true},
+	}
+	for _, tt := range tests {
+		k, v, ok := tree.Floor(tt.key)
+		if ok != tt.floorOK || (ok && (k != tt.floor || v != tt.floor*10)) {
+			t.Errorf("Floor(%d): got %d, %d, %v", tt.key, k, v, ok)
+		}
+		k, v, ok = tree.Ceiling(tt.key)
+		if ok != tt.ceilOK || (ok && (k != tt.ceil || v != tt.ceil*10)) {
+			t.Errorf("Ceiling(%d): got %d, %d, %v", tt.key, k, v, ok)
+		}
+	}
+}
+
+func TestBTree_AscendDescend_AllBounds(t *testing.T) {
+	for _, order := range []int{3, 4, 5} {
+		tree := New[int, int](order)
+		var keys []int
+		for i := 0; i < 200; i += 3 {
+			tree.Insert(i, i*10)
+			keys = append(keys, i)
+		}
+
+		for from := -2; from < 205; from++ {
+			var wantAsc, wantDesc []int
+			for _, k := range keys {
+				if k >= from {
+					wantAsc = append(wantAsc, k)
+				}
+				if k <= from {
+					wantDesc = append([]int{k}, wantDesc...)
+				}
+			}
+			if got := collectKeys(tree.Ascend(from)); !slices.Equal(got, wantAsc) {
+				t.Fatalf("order %d: Ascend(%d): expected %v, got %v", order, from, wantAsc, got)
+			}
+			if got := collectKeys(tree.Descend(from)); !slices.Equal(got, wantDesc) {
+				t.Fatalf("order %d: Descend(%d): expected %v, got %v", order, from, wantDesc, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package btree
+
+// Len returns the number of keys in the tree. In a multimap each key is
+// counted once however many values it holds.
+func (b *BTree[K, V]) Len() int {
+	if b.root == nil {
+		return 0
+	}
+	return b.root.count
+}
+
+// Select returns the k-th smallest key (counting from 0) and its value,
+// or false if k is out of range.
+func (b *BTree[K, V]) Select(k int) (K, V, bool) {
+	if k < 0 || k >= b.Len() {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+
+	node := b.root
+	for {
+		if len(node.children) == 0 {
+			entry := node.entries[k]
+			return entry.key, entry.value, true
+		}
+		// Skip whole subtrees (and the entries after them) until k falls
+		// inside one, or on an entry
+		i := 0
+		for ; k >= node.children[i].count; i++ {
+			k -= node.children[i].count
+			if k == 0 {
+				entry := node.entries[i]
+				return entry.key, entry.value, true
+			}
+			k--
+		}
+		node = node.children[i]
+	}
+}
+
+// Rank returns the number of keys less than key. If key is present this
+// is its position in key order, so Select(Rank(key)) finds it again.
+func (b *BTree[K, V]) Rank(key K) int {
+	rank := 0
+	node := b.root
+	for node != nil {
+		index, found := b.searchEntries(key, node.entries)
+		rank += index
+		if len(node.children) == 0 {
+			return rank
+		}
+		for _, child := range node.children[:index] {
+			rank += child.count
+		}
+		if found {
+			return rank + node.children[index].count
+		}
+		node = node.children[index]
+	}
+	return rank
+}
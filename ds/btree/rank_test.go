@@ -0,0 +1,116 @@
+package btree
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestRank_SelectAndRank(t *testing.T) {
+	tree := buildTree(3, 50, 10, 40, 20, 30)
+
+	if tree.Len() != 5 {
+		t.Fatalf("expected Len 5, got %d", tree.Len())
+	}
+	for i, want := range []int{10, 20, 30, 40, 50} {
+		k, v, ok := tree.Select(i)
+		if !ok || k != want || v != want*10 {
+			t.Errorf("Select(%d) = %d, %d, %v; want %d", i, k, v, ok, want)
+		}
+		if r := tree.Rank(want); r != i {
+			t.Errorf("Rank(%d) = %d, want %d", want, r, i)
+		}
+	}
+
+	for _, k := range []int{-1, 5} {
+		if _, _, ok := tree.Select(k); ok {
+			t.Errorf("expected Select(%d) to be out of range", k)
+		}
+	}
+
+	// Absent keys rank where they would be inserted
+	for key, want := range map[int]int{0: 0, 15: 1, 35: 3, 99: 5} {
+		if r := tree.Rank(key); r != want {
+			t.Errorf("Rank(%d) = %d, want %d", key, r, want)
+		}
+	}
+}
+
+func TestRank_Empty(t *testing.T) {
+	tree := New[int, int](4)
+	if tree.Len() != 0 {
+		t.Errorf("expected Len 0, got %d", tree.Len())
+	}
+	if _, _, ok := tree.Select(0); ok {
+		t.Error("expected Select(0) on an empty tree to fail")
+	}
+	if r := tree.Rank(10); r != 0 {
+		t.Errorf("expected Rank 0, got %d", r)
+	}
+}
+
+func TestRank_RandomInsertDelete(t *testing.T) {
+	for _, order := range []int{3, 4, 5, 8} {
+		rng := rand.New(rand.NewPCG(uint64(order), 11))
+		tree := New[int, int](order)
+		present := make(map[int]bool)
+
+		for i := 0; i < 3000; i++ {
+			k := rng.IntN(400)
+			if rng.IntN(3) == 0 {
+				tree.Delete(k)
+				delete(present, k)
+			} else {
+				tree.Insert(k, k)
+				present[k] = true
+			}
+
+			if i%100 == 0 {
+				checkInvariants(t, tree)
+				var keys []int
+				for k := range present {
+					keys = append(keys, k)
+				}
+				slices.Sort(keys)
+				if tree.Len() != len(keys) {
+					t.Fatalf("order %d: Len %d, want %d", order, tree.Len(), len(keys))
+				}
+				for i, k := range keys {
+					if got, _, _ := tree.Select(i); got != k {
+						t.Fatalf("order %d: Select(%d) = %d, want %d", order, i, got, k)
+					}
+					if r := tree.Rank(k); r != i {
+						t.Fatalf("order %d: Rank(%d) = %d, want %d", order, k, r, i)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRank_MultimapAndSnapshot(t *testing.T) {
+	tree := New[string, int](3, WithMultimap())
+	tree.Insert("a", 1)
+	tree.Insert("b", 2)
+	tree.Insert("b", 3)
+	if tree.Len() != 2 {
+		t.Errorf("expected duplicates to count once, got Len %d", tree.Len())
+	}
+
+	snap := tree.Snapshot()
+	tree.Insert("c", 4)
+	tree.Delete("a")
+
+	if snap.Len() != 2 || tree.Len() != 2 {
+		t.Errorf("expected Len 2 for both, got snapshot %d, tree %d", snap.Len(), tree.Len())
+	}
+	if k, v, _ := snap.Select(1); k != "b" || v != 2 {
+		t.Errorf("snapshot Select(1) = %q, %d", k, v)
+	}
+	if r := snap.Rank("b"); r != 1 {
+		t.Errorf("snapshot Rank(b) = %d, want 1", r)
+	}
+	if r := tree.Rank("b"); r != 0 {
+		t.Errorf("tree Rank(b) = %d, want 0", r)
+	}
+}
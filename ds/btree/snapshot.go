@@ -0,0 +1,81 @@
+package btree
+
+import "iter"
+
+// Snapshot is an immutable, point-in-time view of a BTree. It shares
+// nodes with the tree it was taken from; the tree copies a shared node
+// before modifying it, so later writes never show through. A Snapshot is
+// safe to read from other goroutines while the tree keeps being written.
+type Snapshot[K, V any] struct {
+	tree *BTree[K, V]
+}
+
+// Snapshot returns a read-only view of the tree's current contents in
+// O(1). Subsequent writes to b copy only the nodes they touch.
+func (b *BTree[K, V]) Snapshot() *Snapshot[K, V] {
+	frozen := *b
+	// Hand b a new generation so every node reachable from the snapshot
+	// now counts as shared
+	b.cow = new(copyOnWriteContext)
+	return &Snapshot[K, V]{tree: &frozen}
+}
+
+func (s *Snapshot[K, V]) Search(key K) (V, bool) {
+	return s.tree.Search(key)
+}
+
+func (s *Snapshot[K, V]) SearchAll(key K) []V {
+	return s.tree.SearchAll(key)
+}
+
+func (s *Snapshot[K, V]) All() iter.Seq2[K, V] {
+	return s.tree.All()
+}
+
+func (s *Snapshot[K, V]) Ascend(from K) iter.Seq2[K, V] {
+	return s.tree.Ascend(from)
+}
+
+func (s *Snapshot[K, V]) Descend(from K) iter.Seq2[K, V] {
+	return s.tree.Descend(from)
+}
+
+func (s *Snapshot[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return s.tree.Range(lo, hi)
+}
+
+func (s *Snapshot[K, V]) Min() (K, V, bool) {
+	return s.tree.Min()
+}
+
+func (s *Snapshot[K, V]) Max() (K, V, bool) {
+	return s.tree.Max()
+}
+
+func (s *Snapshot[K, V]) Floor(key K) (K, V, bool) {
+	return s.tree.Floor(key)
+}
+
+func (s *Snapshot[K, V]) Ceiling(key K) (K, V, bool) {
+	return s.tree.Ceiling(key)
+}
+
+func (s *Snapshot[K, V]) Len() int {
+	return s.tree.Len()
+}
+
+func (s *Snapshot[K, V]) Select(k int) (K, V, bool) {
+	return s.tree.Select(k)
+}
+
+func (s *Snapshot[K, V]) Rank(key K) int {
+	return s.tree.Rank(key)
+}
+
+func (s *Snapshot[K, V]) Validate() error {
+	return s.tree.Validate()
+}
+
+func (s *Snapshot[K, V]) Stats() Stats {
+	return s.tree.Stats()
+}
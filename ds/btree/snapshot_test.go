@@ -0,0 +1,215 @@
+package btree
+
+import (
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func checkContents(t *testing.T, snap *Snapshot[int, int], want map[int]int) {
+	t.Helper()
+	got := make(map[int]int)
+	for k, v := range snap.All() {
+		got[k] = v
+	}
+	if !maps.Equal(got, want) {
+		t.Fatalf("snapshot contents changed: expected %d keys, got %d", len(want), len(got))
+	}
+	for k, v := range want {
+		if val, found := snap.Search(k); !found || val != v {
+			t.Fatalf("key %d: found=%v, val=%v, want %v", k, found, val, v)
+		}
+	}
+}
+
+func TestSnapshot_IsolatedFromWrites(t *testing.T) {
+	tree := New[int, int](3)
+	expected := make(map[int]int)
+	for i := 0; i < 100; i++ {
+		tree.Insert(i, i)
+		expected[i] = i
+	}
+
+	snap := tree.Snapshot()
+	frozen := maps.Clone(expected)
+
+	// Replace, insert and delete enough to split, borrow and merge
+	for i := 0; i < 100; i += 2 {
+		tree.Insert(i, -i)
+		expected[i] = -i
+	}
+	for i := 100; i < 200; i++ {
+		tree.Insert(i, i)
+		expected[i] = i
+	}
+	for i := 0; i < 150; i += 3 {
+		tree.Delete(i)
+		delete(expected, i)
+	}
+
+	checkContents(t, snap, frozen)
+	checkInvariants(t, snap.tree)
+	checkInvariants(t, tree)
+	checkContents(t, tree.Snapshot(), expected)
+}
+
+func TestSnapshot_Random(t *testing.T) {
+	rng := rand.New(rand.NewPCG(9, 9))
+	tree := New[int, int](4)
+	expected := make(map[int]int)
+
+	type frozen struct {
+		snap *Snapshot[int, int]
+		want map[int]int
+	}
+	var snaps []frozen
+
+	for i := 0; i < 3000; i++ {
+		k := rng.IntN(300)
+		if rng.IntN(3) == 0 {
+			tree.Delete(k)
+			delete(expected, k)
+		} else {
+			tree.Insert(k, i)
+			expected[k] = i
+		}
+		if i%250 == 0 {
+			snaps = append(snaps, frozen{tree.Snapshot(), maps.Clone(expected)})
+		}
+	}
+
+	for _, f := range snaps {
+		checkContents(t, f.snap, f.want)
+		checkInvariants(t, f.snap.tree)
+	}
+}
+
+func TestSnapshot_Multimap(t *testing.T) {
+	tree := New[string, int](3, WithMultimap())
+	tree.Insert("a", 1)
+	tree.Insert("a", 2)
+
+	snap := tree.Snapshot()
+	tree.Insert("a", 3)
+
+	if got := snap.SearchAll("a"); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("expected snapshot to keep [1 2], got %v", got)
+	}
+	if got := tree.SearchAll("a"); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("expected tree to have [1 2 3], got %v", got)
+	}
+}
+
+func TestSnapshot_CopiesOnlyTouchedPath(t *testing.T) {
+	tree := New[int, int](4)
+	for i := 0; i < 1000; i++ {
+		tree.Insert(i, i)
+	}
+	snap := tree.Snapshot()
+
+	// Replacing a value in a leaf copies just the root-to-leaf path
+	tree.Insert(500, -1)
+
+	shared := make(map[*Node[int, int]]bool)
+	var walk func(node *Node[int, int], visit func(*Node[int, int]))
+	walk = func(node *Node[int, int], visit func(*Node[int, int])) {
+		visit(node)
+		for _, child := range node.children {
+			walk(child, visit)
+		}
+	}
+	walk(snap.tree.root, func(n *Node[int, int]) { shared[n] = true })
+
+	copied, height := 0, 0
+	walk(tree.root, func(n *Node[int, int]) {
+		if !shared[n] {
+			copied++
+		}
+	})
+	for node := tree.root; node != nil; height++ {
+		if len(node.children) == 0 {
+			node = nil
+		} else {
+			node = node.children[0]
+		}
+	}
+	if copied > height {
+		t.Errorf("expected at most %d copied nodes, got %d", height, copied)
+	}
+	if val, _ := snap.Search(500); val != 500 {
+		t.Errorf("expected snapshot value 500, got %d", val)
+	}
+}
+
+func TestSnapshot_ConcurrentReaders(t *testing.T) {
+	tree := New[int, int](8)
+	for i := 0; i < 2000; i++ {
+		tree.Insert(i, i)
+	}
+	snap := tree.Snapshot()
+
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pass := 0; pass < 20; pass++ {
+				count, prev := 0, -1
+				for k, v := range snap.All() {
+					if k != v || k <= prev {
+						t.Errorf("inconsistent snapshot entry %d=%d after %d", k, v, prev)
+						return
+					}
+					prev = k
+					count++
+				}
+				if count != 2000 {
+					t.Errorf("expected 2000 keys, got %d", count)
+					return
+				}
+			}
+		}()
+	}
+
+	// Writer keeps going while the readers scan
+	for i := 0; i < 4000; i++ {
+		if i%2 == 0 {
+			tree.Delete(i / 2)
+		} else {
+			tree.Insert(2000+i, -i)
+		}
+	}
+	wg.Wait()
+}
+
+func TestSnapshot_ReadMethods(t *testing.T) {
+	tree := buildTree(3, 10, 20, 30, 40, 50)
+	snap := tree.Snapshot()
+	tree.Delete(10)
+	tree.Delete(50)
+	tree.Insert(35, 350)
+
+	if k, _, _ := snap.Min(); k != 10 {
+		t.Errorf("Min: expected 10, got %d", k)
+	}
+	if k, _, _ := snap.Max(); k != 50 {
+		t.Errorf("Max: expected 50, got %d", k)
+	}
+	if k, _, _ := snap.Floor(36); k != 30 {
+		t.Errorf("Floor(36): expected 30, got %d", k)
+	}
+	if k, _, _ := snap.Ceiling(31); k != 40 {
+		t.Errorf("Ceiling(31): expected 40, got %d", k)
+	}
+	if got := collectKeys(snap.Ascend(25)); !slices.Equal(got, []int{30, 40, 50}) {
+		t.Errorf("Ascend(25): got %v", got)
+	}
+	if got := collectKeys(snap.Descend(25)); !slices.Equal(got, []int{20, 10}) {
+		t.Errorf("Descend(25): got %v", got)
+	}
+	if got := collectKeys(snap.Range(20, 50)); !slices.Equal(got, []int{20, 30, 40}) {
+		t.Errorf("Range(20, 50): got %v", got)
+	}
+}
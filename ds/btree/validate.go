@@ -0,0 +1,98 @@
+package btree
+
+import "fmt"
+
+// Stats describes the shape of a tree.
+type Stats struct {
+	Height int
+	Nodes  int
+	Keys   int
+	// FillFactor is the fraction of all entry slots (order-1 per node)
+	// that are in use
+	FillFactor float64
+}
+
+// Validate checks the tree's structural invariants and returns an error
+// describing the first violation found: keys out of order, a node outside
+// its occupancy bounds, leaves at different depths, an internal node
+// without exactly one more child than entries, or a wrong subtree count.
+func (b *BTree[K, V]) Validate() error {
+	if b.root == nil {
+		return nil
+	}
+	leafDepth := -1
+	return b.validateNode(b.root, 0, nil, nil, &leafDepth)
+}
+
+func (b *BTree[K, V]) validateNode(node *Node[K, V], depth int, lo, hi *K, leafDepth *int) error {
+	if len(node.entries) > b.order-1 || len(node.entries) == 0 {
+		return fmt.Errorf("btree: node at depth %d has %d entries, outside [1, %d]", depth, len(node.entries), b.order-1)
+	}
+	if node != b.root && len(node.entries) < b.minEntries() {
+		return fmt.Errorf("btree: node at depth %d has %d entries, below minimum %d", depth, len(node.entries), b.minEntries())
+	}
+	for i, e := range node.entries {
+		if i > 0 && b.compare(node.entries[i-1].key, e.key) >= 0 {
+			return fmt.Errorf("btree: node at depth %d has key %v after %v", depth, e.key, node.entries[i-1].key)
+		}
+		if (lo != nil && b.compare(e.key, *lo) <= 0) || (hi != nil && b.compare(e.key, *hi) >= 0) {
+			return fmt.Errorf("btree: node at depth %d has key %v outside its parent's separators", depth, e.key)
+		}
+		if len(e.dups) > 0 && !b.multi {
+			return fmt.Errorf("btree: key %v has duplicate values outside multimap mode", e.key)
+		}
+	}
+
+	count := len(node.entries)
+	if len(node.children) == 0 {
+		if *leafDepth < 0 {
+			*leafDepth = depth
+		} else if depth != *leafDepth {
+			return fmt.Errorf("btree: leaf at depth %d, expected %d", depth, *leafDepth)
+		}
+	} else {
+		if len(node.children) != len(node.entries)+1 {
+			return fmt.Errorf("btree: node at depth %d has %d children for %d entries", depth, len(node.children), len(node.entries))
+		}
+		for i, child := range node.children {
+			childLo, childHi := lo, hi
+			if i > 0 {
+				childLo = &node.entries[i-1].key
+			}
+			if i < len(node.entries) {
+				childHi = &node.entries[i].key
+			}
+			if err := b.validateNode(child, depth+1, childLo, childHi, leafDepth); err != nil {
+				return err
+			}
+			count += child.count
+		}
+	}
+	if node.count != count {
+		return fmt.Errorf("btree: node at depth %d has count %d, expected %d", depth, node.count, count)
+	}
+	return nil
+}
+
+// Stats walks the tree and reports its height, node count, key count and
+// fill factor.
+func (b *BTree[K, V]) Stats() Stats {
+	var stats Stats
+	if b.root == nil {
+		return stats
+	}
+
+	var walk func(node *Node[K, V], depth int)
+	walk = func(node *Node[K, V], depth int) {
+		stats.Nodes++
+		stats.Keys += len(node.entries)
+		stats.Height = max(stats.Height, depth)
+		for _, child := range node.children {
+			walk(child, depth+1)
+		}
+	}
+	walk(b.root, 1)
+
+	stats.FillFactor = float64(stats.Keys) / float64(stats.Nodes*(b.order-1))
+	return stats
+}
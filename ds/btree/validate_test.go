@@ -0,0 +1,105 @@
+package btree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate_DetectsCorruption(t *testing.T) {
+	cases := []struct {
+		name    string
+		corrupt func(tree *BTree[int, int])
+		want    string
+	}{
+		{"out of order", func(tree *BTree[int, int]) {
+			leaf := tree.root.children[0]
+			leaf.entries[0].key, leaf.entries[1].key = leaf.entries[1].key, leaf.entries[0].key
+		}, "after"},
+		{"outside separators", func(tree *BTree[int, int]) {
+			tree.root.children[0].entries[0].key = 1000
+		}, "separators"},
+		{"underfull", func(tree *BTree[int, int]) {
+			leaf := tree.root.children[0]
+			leaf.entries = leaf.entries[:1]
+		}, "below minimum"},
+		{"overfull", func(tree *BTree[int, int]) {
+			leaf := tree.root.children[0]
+			for i := 0; i < tree.order; i++ {
+				leaf.entries = append(leaf.entries, Entry[int, int]{key: -1})
+			}
+		}, "outside [1,"},
+		{"missing child", func(tree *BTree[int, int]) {
+			tree.root.children = tree.root.children[:len(tree.root.children)-1]
+		}, "children"},
+		{"uneven leaves", func(tree *BTree[int, int]) {
+			// Hang a well-formed leaf below every gap of the first leaf,
+			// keeping the counts on its path right
+			path := []*Node[int, int]{tree.root}
+			for len(path[len(path)-1].children) > 0 {
+				path = append(path, path[len(path)-1].children[0])
+			}
+			leaf := path[len(path)-1]
+			for i := 0; i <= len(leaf.entries); i++ {
+				key := -5
+				if i > 0 {
+					key = leaf.entries[i-1].key + 5
+				}
+				leaf.children = append(leaf.children, &Node[int, int]{entries: []Entry[int, int]{{key: key}, {key: key + 1}}, count: 2})
+			}
+			for _, node := range path {
+				node.count += 2 * len(leaf.children)
+			}
+		}, "leaf at depth"},
+		{"wrong count", func(tree *BTree[int, int]) {
+			tree.root.children[1].count++
+		}, "count"},
+	}
+
+	for _, c := range cases {
+		tree := New[int, int](5)
+		for i := 0; i < 20; i++ {
+			tree.Insert(i*10, i)
+		}
+		if err := tree.Validate(); err != nil {
+			t.Fatalf("%s: valid tree failed validation: %v", c.name, err)
+		}
+
+		c.corrupt(tree)
+		err := tree.Validate()
+		if err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		} else if !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: expected error containing %q, got %v", c.name, c.want, err)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	tree := New[int, int](4)
+	if s := tree.Stats(); s != (Stats{}) {
+		t.Errorf("expected zero stats for an empty tree, got %+v", s)
+	}
+
+	tree.BulkLoad(sortedSeq(3), 1)
+	if s := tree.Stats(); s.Height != 1 || s.Nodes != 1 || s.Keys != 3 || s.FillFactor != 1 {
+		t.Errorf("expected one full node, got %+v", s)
+	}
+
+	tree = New[int, int](4)
+	for i := 0; i < 1000; i++ {
+		tree.Insert(i, i)
+	}
+	s := tree.Stats()
+	if s.Keys != 1000 || s.Keys != tree.Len() {
+		t.Errorf("expected 1000 keys, got %d", s.Keys)
+	}
+	if s.Height < 5 || s.Height > 10 {
+		t.Errorf("unexpected height %d for 1000 keys at order 4", s.Height)
+	}
+	if s.FillFactor <= 0.33 || s.FillFactor > 1 {
+		t.Errorf("fill factor %v out of range", s.FillFactor)
+	}
+	if snap := tree.Snapshot(); snap.Stats() != s || snap.Validate() != nil {
+		t.Error("expected snapshot to report the same stats and validate")
+	}
+}
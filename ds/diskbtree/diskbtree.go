@@ -0,0 +1,670 @@
+package diskbtree
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+const (
+	defaultPoolPages       = 64
+	defaultCheckpointEvery = 1024
+)
+
+// BTree is a B-tree of int64 keys and values whose nodes are stored as
+// fixed-size pages in a file.
+//
+// Every Insert and Delete is first appended to a write-ahead log next to
+// the file (path + ".wal"). Changed nodes stay in the buffer pool until a
+// checkpoint logs their page images, writes them to the file and
+// truncates the log. On Open the last complete checkpoint is re-applied
+// and any logged operations after it are replayed, so a crash at any
+// point (including mid-split) recovers a consistent tree. After an I/O
+// error the tree should be closed and reopened to recover.
+// It is not thread-safe.
+type BTree struct {
+	pager *pager
+	pool  *bufferPool
+	wal   *wal
+	meta  meta
+	order int
+	buf   []byte
+
+	// pendingFree holds pages freed since the last checkpoint; they join
+	// the on-disk free list when the checkpoint is written
+	pendingFree     []uint32
+	checkpointEvery int
+
+	// unapplied is set when a checkpoint committed to the log failed to
+	// reach the data file. The log then holds the only copy of its pages,
+	// so no further checkpoint may truncate it; reopening recovers them.
+	unapplied error
+}
+
+type config struct {
+	syncPolicy      SyncPolicy
+	poolPages       int
+	checkpointEvery int
+}
+
+// Option configures a BTree.
+type Option func(*config)
+
+// WithSyncPolicy sets when the write-ahead log is fsynced. The default is SyncAlways.
+func WithSyncPolicy(p SyncPolicy) Option {
+	return func(c *config) {
+		c.syncPolicy = p
+	}
+}
+
+// WithBufferPages sets how many nodes the buffer pool caches. The pool
+// may hold more while they are dirty; exceeding it forces a checkpoint.
+func WithBufferPages(n int) Option {
+	return func(c *config) {
+		c.poolPages = n
+	}
+}
+
+// WithCheckpointEvery sets how many logged operations trigger a
+// checkpoint, bounding the log size and recovery time.
+func WithCheckpointEvery(n int) Option {
+	return func(c *config) {
+		c.checkpointEvery = n
+	}
+}
+
+// Open opens the tree stored at path, creating an empty one if the file
+// does not exist, and recovers from its write-ahead log. pageSize must
+// match the size the file was created with.
+func Open(path string, pageSize int, opts ...Option) (*BTree, error) {
+	if pageSize < MinPageSize || pageSize > MaxPageSize {
+		return nil, fmt.Errorf("diskbtree: page size %d out of range [%d, %d]", pageSize, MinPageSize, MaxPageSize)
+	}
+	cfg := config{
+		syncPolicy:      SyncAlways,
+		poolPages:       defaultPoolPages,
+		checkpointEvery: defaultCheckpointEvery,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	walFile, err := os.OpenFile(path+".wal", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	t, err := open(file, walFile, pageSize, cfg)
+	if err != nil {
+		file.Close()
+		walFile.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+func open(file, walFile *os.File, pageSize int, cfg config) (*BTree, error) {
+	p := &pager{
+		file:     file,
+		pageSize: pageSize,
+	}
+	t := &BTree{
+		pager: p,
+		pool:  newBufferPool(p, cfg.poolPages),
+		wal: &wal{
+			file:   walFile,
+			policy: cfg.syncPolicy,
+		},
+		order:           maxEntries(pageSize) + 1,
+		buf:             make([]byte, pageSize),
+		checkpointEvery: cfg.checkpointEvery,
+	}
+
+	records, err := t.wal.read(pageSize)
+	if err != nil {
+		return nil, err
+	}
+	ops, recovered, err := t.redoCheckpoint(records)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.readMeta(pageSize); err != nil {
+		return nil, err
+	}
+
+	// Replay operations logged after the last checkpoint, then checkpoint
+	// so the log only ever holds work newer than the data file. Page images
+	// of a checkpoint that crashed before its commit are dropped: the
+	// operations before them are replayed instead.
+	for _, rec := range ops {
+		switch rec.typ {
+		case recInsert:
+			err = t.insert(entry{key: rec.key, value: rec.value})
+		case recDelete:
+			_, err = t.delete(rec.key)
+		default:
+			continue
+		}
+		t.pool.release()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if recovered || len(ops) > 0 {
+		if err := t.Checkpoint(); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// redoCheckpoint re-applies the page images of the last complete
+// checkpoint in the log, in case it crashed while writing them to the
+// data file, and returns the operations logged after it.
+func (t *BTree) redoCheckpoint(records []record) ([]record, bool, error) {
+	last := -1
+	for i, rec := range records {
+		if rec.typ == recCommit {
+			last = i
+		}
+	}
+	if last < 0 {
+		return records, false, nil
+	}
+
+	for _, rec := range records[:last] {
+		if rec.typ == recPage {
+			if err := t.pager.write(rec.id, rec.page); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	if err := t.pager.file.Sync(); err != nil {
+		return nil, false, err
+	}
+	return records[last+1:], true, nil
+}
+
+func (t *BTree) readMeta(pageSize int) error {
+	info, err := t.pager.file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		t.meta = meta{
+			pageSize:  uint32(pageSize),
+			pageCount: 1,
+		}
+		encodeMeta(t.buf, t.meta)
+		if err := t.pager.write(0, t.buf); err != nil {
+			return err
+		}
+		return t.pager.file.Sync()
+	}
+
+	// Check the recorded page size before trusting a full page read
+	if err := t.pager.read(0, t.buf[:32]); err != nil {
+		return err
+	}
+	if size := binary.LittleEndian.Uint32(t.buf[16:]); size != uint32(pageSize) {
+		return fmt.Errorf("diskbtree: file has page size %d, not %d", size, pageSize)
+	}
+	if err := t.pager.read(0, t.buf); err != nil {
+		return err
+	}
+	t.meta, err = decodeMeta(t.buf)
+	return err
+}
+
+// Sync flushes the write-ahead log to stable storage, making every
+// completed Insert and Delete durable regardless of the sync policy.
+func (t *BTree) Sync() error {
+	return t.wal.sync()
+}
+
+// Checkpoint writes every changed node to the data file and truncates the
+// write-ahead log. The page images are logged and synced first, so a
+// crash part way through writing them is repaired on the next Open.
+func (t *BTree) Checkpoint() error {
+	if t.unapplied != nil {
+		return t.unapplied
+	}
+	images, err := t.logCheckpoint()
+	if err != nil {
+		return err
+	}
+
+	// The checkpoint is now durable in the log; apply it to the data file
+	for _, img := range images {
+		if err = t.pager.write(img.id, img.page); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = t.pager.file.Sync()
+	}
+	if err != nil {
+		t.unapplied = fmt.Errorf("diskbtree: checkpoint not applied, reopen to recover: %w", err)
+		return t.unapplied
+	}
+	return t.wal.truncate()
+}
+
+type pageImage struct {
+	id   uint32
+	page []byte
+}
+
+// logCheckpoint appends the images of every changed page, then a commit
+// record, and syncs the log. Only once the log is synced are the nodes
+// marked clean and the freed pages linked into the free list, so a
+// failed checkpoint leaves everything in place to be logged again.
+func (t *BTree) logCheckpoint() ([]pageImage, error) {
+	var images []pageImage
+	newImage := func(id uint32) []byte {
+		images = append(images, pageImage{id: id, page: make([]byte, len(t.buf))})
+		return images[len(images)-1].page
+	}
+
+	nodes := t.pool.dirty()
+	for _, n := range nodes {
+		encodeNode(newImage(n.id), n)
+	}
+	// Link freed pages onto the free list; the meta page that points at
+	// them goes in the same checkpoint
+	meta := t.meta
+	for _, id := range t.pendingFree {
+		encodeFree(newImage(id), meta.freeHead)
+		meta.freeHead = id
+	}
+	encodeMeta(newImage(0), meta)
+
+	for _, img := range images {
+		if err := t.wal.appendPage(img.id, img.page); err != nil {
+			return nil, err
+		}
+	}
+	if err := t.wal.append(recCommit, nil); err != nil {
+		return nil, err
+	}
+	if err := t.wal.sync(); err != nil {
+		return nil, err
+	}
+
+	for _, n := range nodes {
+		n.dirty = false
+	}
+	t.meta = meta
+	t.pendingFree = t.pendingFree[:0]
+	t.pool.release()
+	return images, nil
+}
+
+// Close checkpoints the tree and closes its files.
+func (t *BTree) Close() error {
+	err := t.Checkpoint()
+	return errors.Join(err, t.pager.file.Close(), t.wal.file.Close())
+}
+
+// Insert stores value under key, replacing any previous value.
+func (t *BTree) Insert(key, value int64) error {
+	if err := t.wal.appendOp(recInsert, key, value); err != nil {
+		return err
+	}
+	err := t.insert(entry{key: key, value: value})
+	t.pool.release()
+	if err != nil {
+		return err
+	}
+	return t.maybeCheckpoint()
+}
+
+func (t *BTree) Search(key int64) (int64, bool, error) {
+	value, found, err := t.search(key)
+	t.pool.release()
+	return value, found, err
+}
+
+// Delete removes key, reporting whether it was present.
+func (t *BTree) Delete(key int64) (bool, error) {
+	if err := t.wal.appendOp(recDelete, key, 0); err != nil {
+		return false, err
+	}
+	found, err := t.delete(key)
+	t.pool.release()
+	if err != nil {
+		return found, err
+	}
+	return found, t.maybeCheckpoint()
+}
+
+func (t *BTree) maybeCheckpoint() error {
+	// Dirty nodes cannot be evicted, so checkpoint once they fill the pool
+	if t.wal.records >= t.checkpointEvery || t.pool.lruList.Len() > t.pool.capacity {
+		return t.Checkpoint()
+	}
+	return nil
+}
+
+func (t *BTree) insert(e entry) error {
+	if t.meta.root == 0 {
+		root, err := t.allocNode()
+		if err != nil {
+			return err
+		}
+		root.entries = append(root.entries, e)
+		t.meta.root = root.id
+		return nil
+	}
+
+	root, err := t.pool.get(t.meta.root)
+	if err != nil {
+		return err
+	}
+	promoted, rightSib, err := t.insertNode(root, e)
+	if err != nil || rightSib == nil {
+		return err
+	}
+
+	// Root overflowed: split it and make tree taller
+	newRoot, err := t.allocNode()
+	if err != nil {
+		return err
+	}
+	newRoot.entries = append(newRoot.entries, promoted)
+	newRoot.children = append(newRoot.children, root.id, rightSib.id)
+	t.meta.root = newRoot.id
+	return nil
+}
+
+func (t *BTree) search(key int64) (int64, bool, error) {
+	for id := t.meta.root; id != 0; {
+		n, err := t.pool.get(id)
+		if err != nil {
+			return 0, false, err
+		}
+		index, found := searchEntries(key, n.entries)
+		if found {
+			return n.entries[index].value, true, nil
+		}
+		if n.leaf() {
+			break
+		}
+		id = n.children[index]
+	}
+	return 0, false, nil
+}
+
+func (t *BTree) delete(key int64) (bool, error) {
+	if t.meta.root == 0 {
+		return false, nil
+	}
+
+	root, err := t.pool.get(t.meta.root)
+	if err != nil {
+		return false, err
+	}
+	found, err := t.deleteNode(root, key)
+	if err != nil || len(root.entries) > 0 {
+		return found, err
+	}
+
+	// Root emptied by a merge (or last key removed): make tree shorter
+	if root.leaf() {
+		t.meta.root = 0
+	} else {
+		t.meta.root = root.children[0]
+	}
+	t.freeNode(root)
+	return found, nil
+}
+
+func (t *BTree) maxEntries() int {
+	return t.order - 1
+}
+
+// minEntries is the fewest entries a non-root node may hold: ceil(order/2) - 1.
+func (t *BTree) minEntries() int {
+	return (t.order+1)/2 - 1
+}
+
+func searchEntries(key int64, entries []entry) (int, bool) {
+	return slices.BinarySearchFunc(entries, key, func(e entry, key int64) int {
+		switch {
+		case e.key < key:
+			return -1
+		case e.key > key:
+			return 1
+		}
+		return 0
+	})
+}
+
+func (t *BTree) allocNode() (*node, error) {
+	// Reuse a freed page before growing the file
+	var id uint32
+	if n := len(t.pendingFree); n > 0 {
+		id = t.pendingFree[n-1]
+		t.pendingFree = t.pendingFree[:n-1]
+	} else if id = t.meta.freeHead; id != 0 {
+		if err := t.pager.read(id, t.buf); err != nil {
+			return nil, err
+		}
+		next, err := decodeFree(id, t.buf)
+		if err != nil {
+			return nil, err
+		}
+		t.meta.freeHead = next
+	} else {
+		id = t.meta.pageCount
+		t.meta.pageCount++
+	}
+
+	return t.pool.add(&node{
+		id:      id,
+		entries: make([]entry, 0, t.order),
+	}), nil
+}
+
+func (t *BTree) freeNode(n *node) {
+	t.pool.drop(n.id)
+	t.pendingFree = append(t.pendingFree, n.id)
+}
+
+func (t *BTree) insertNode(n *node, e entry) (entry, *node, error) {
+	// Insert into the subtree rooted at n. If n overflows it is split and
+	// the promoted entry and new right sibling are returned to the parent.
+	index, found := searchEntries(e.key, n.entries)
+	if found {
+		n.entries[index].value = e.value
+		n.dirty = true
+		return entry{}, nil, nil
+	}
+
+	if n.leaf() {
+		n.entries = slices.Insert(n.entries, index, e)
+		n.dirty = true
+	} else {
+		child, err := t.pool.get(n.children[index])
+		if err != nil {
+			return entry{}, nil, err
+		}
+		promoted, rightSib, err := t.insertNode(child, e)
+		if err != nil {
+			return entry{}, nil, err
+		}
+		if rightSib != nil {
+			n.entries = slices.Insert(n.entries, index, promoted)
+			n.children = slices.Insert(n.children, index+1, rightSib.id)
+			n.dirty = true
+		}
+	}
+
+	if len(n.entries) <= t.maxEntries() {
+		return entry{}, nil, nil
+	}
+	return t.splitNode(n)
+}
+
+func (t *BTree) splitNode(n *node) (entry, *node, error) {
+	mid := len(n.entries) / 2
+
+	rightSib, err := t.allocNode()
+	if err != nil {
+		return entry{}, nil, err
+	}
+
+	promoted := n.entries[mid]
+	rightSib.entries = append(rightSib.entries, n.entries[mid+1:]...)
+	n.entries = n.entries[:mid]
+	if !n.leaf() {
+		rightSib.children = append(rightSib.children, n.children[mid+1:]...)
+		n.children = n.children[:mid+1]
+	}
+	n.dirty = true
+
+	return promoted, rightSib, nil
+}
+
+func (t *BTree) deleteNode(n *node, key int64) (bool, error) {
+	index, found := searchEntries(key, n.entries)
+	if n.leaf() {
+		if !found {
+			return false, nil
+		}
+		n.entries = slices.Delete(n.entries, index, index+1)
+		n.dirty = true
+		return true, nil
+	}
+
+	child, err := t.pool.get(n.children[index])
+	if err != nil {
+		return false, err
+	}
+	if found {
+		// Replace with the in-order predecessor, which always lives in a leaf
+		pred, err := t.deleteMax(child)
+		if err != nil {
+			return false, err
+		}
+		n.entries[index] = pred
+		n.dirty = true
+	} else {
+		ok, err := t.deleteNode(child, key)
+		if !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, t.rebalance(n, index)
+}
+
+func (t *BTree) deleteMax(n *node) (entry, error) {
+	// Remove and return the largest entry in the subtree rooted at n
+	if n.leaf() {
+		last := len(n.entries) - 1
+		e := n.entries[last]
+		n.entries = n.entries[:last]
+		n.dirty = true
+		return e, nil
+	}
+
+	index := len(n.children) - 1
+	child, err := t.pool.get(n.children[index])
+	if err != nil {
+		return entry{}, err
+	}
+	e, err := t.deleteMax(child)
+	if err != nil {
+		return entry{}, err
+	}
+	return e, t.rebalance(n, index)
+}
+
+func (t *BTree) rebalance(n *node, index int) error {
+	// Restore minimum occupancy of n.children[index] after a removal,
+	// preferring to borrow from a sibling and merging only if neither can spare one
+	child, err := t.pool.get(n.children[index])
+	if err != nil {
+		return err
+	}
+	minEntries := t.minEntries()
+	if len(child.entries) >= minEntries {
+		return nil
+	}
+
+	var leftSib, rightSib *node
+	if index > 0 {
+		if leftSib, err = t.pool.get(n.children[index-1]); err != nil {
+			return err
+		}
+		if len(leftSib.entries) > minEntries {
+			t.borrowFromLeft(n, index, child, leftSib)
+			return nil
+		}
+	}
+	if index < len(n.children)-1 {
+		if rightSib, err = t.pool.get(n.children[index+1]); err != nil {
+			return err
+		}
+		if len(rightSib.entries) > minEntries {
+			t.borrowFromRight(n, index, child, rightSib)
+			return nil
+		}
+	}
+
+	if leftSib != nil {
+		t.mergeChildren(n, index-1, leftSib, child)
+	} else {
+		t.mergeChildren(n, index, child, rightSib)
+	}
+	return nil
+}
+
+func (t *BTree) borrowFromLeft(n *node, index int, child, leftSib *node) {
+	// Rotate right: separator moves down into child, left sibling's last entry moves up
+	last := len(leftSib.entries) - 1
+	child.entries = slices.Insert(child.entries, 0, n.entries[index-1])
+	n.entries[index-1] = leftSib.entries[last]
+	leftSib.entries = leftSib.entries[:last]
+
+	if !leftSib.leaf() {
+		last = len(leftSib.children) - 1
+		child.children = slices.Insert(child.children, 0, leftSib.children[last])
+		leftSib.children = leftSib.children[:last]
+	}
+	n.dirty, child.dirty, leftSib.dirty = true, true, true
+}
+
+func (t *BTree) borrowFromRight(n *node, index int, child, rightSib *node) {
+	// Rotate left: separator moves down into child, right sibling's first entry moves up
+	child.entries = append(child.entries, n.entries[index])
+	n.entries[index] = rightSib.entries[0]
+	rightSib.entries = slices.Delete(rightSib.entries, 0, 1)
+
+	if !rightSib.leaf() {
+		child.children = append(child.children, rightSib.children[0])
+		rightSib.children = slices.Delete(rightSib.children, 0, 1)
+	}
+	n.dirty, child.dirty, rightSib.dirty = true, true, true
+}
+
+func (t *BTree) mergeChildren(n *node, index int, leftSib, rightSib *node) {
+	// Merge rightSib and the separator between them into leftSib and free rightSib's page
+	leftSib.entries = append(leftSib.entries, n.entries[index])
+	leftSib.entries = append(leftSib.entries, rightSib.entries...)
+	leftSib.children = append(leftSib.children, rightSib.children...)
+
+	n.entries = slices.Delete(n.entries, index, index+1)
+	n.children = slices.Delete(n.children, index+1, index+2)
+	n.dirty, leftSib.dirty = true, true
+
+	t.freeNode(rightSib)
+}
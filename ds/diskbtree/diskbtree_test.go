@@ -0,0 +1,265 @@
+package diskbtree
+
+import (
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func openTree(t *testing.T, path string, pageSize int, opts ...Option) *BTree {
+	t.Helper()
+	tree, err := Open(path, pageSize, opts...)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return tree
+}
+
+func mustSearch(t *testing.T, tree *BTree, key int64) (int64, bool) {
+	t.Helper()
+	value, found, err := tree.Search(key)
+	if err != nil {
+		t.Fatalf("Search(%d): %v", key, err)
+	}
+	return value, found
+}
+
+func mustInsert(t *testing.T, tree *BTree, key, value int64) {
+	t.Helper()
+	if err := tree.Insert(key, value); err != nil {
+		t.Fatalf("Insert(%d): %v", key, err)
+	}
+}
+
+func mustDelete(t *testing.T, tree *BTree, key int64) bool {
+	t.Helper()
+	found, err := tree.Delete(key)
+	if err != nil {
+		t.Fatalf("Delete(%d): %v", key, err)
+	}
+	return found
+}
+
+func TestDiskBTree_InsertAndSearch(t *testing.T) {
+	tree := openTree(t, filepath.Join(t.TempDir(), "tree.db"), 4096)
+	defer tree.Close()
+
+	if err := tree.Insert(10, 100); err != nil {
+		t.Fatal(err)
+	}
+	if v, found := mustSearch(t, tree, 10); !found || v != 100 {
+		t.Errorf("key 10: found=%v, val=%v", found, v)
+	}
+	if _, found := mustSearch(t, tree, 99); found {
+		t.Error("expected not to find key 99")
+	}
+
+	// Replace
+	if err := tree.Insert(10, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := mustSearch(t, tree, 10); v != 1000 {
+		t.Errorf("expected 1000, got %d", v)
+	}
+}
+
+func TestDiskBTree_SurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	tree := openTree(t, path, 128) // 5 entries per node, so the tree gets tall
+
+	for i := int64(0); i < 1000; i++ {
+		if err := tree.Insert(i, i*2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tree = openTree(t, path, 128)
+	for i := int64(0); i < 1000; i++ {
+		if v, found := mustSearch(t, tree, i); !found || v != i*2 {
+			t.Fatalf("key %d: found=%v, val=%v", i, found, v)
+		}
+	}
+
+	for i := int64(0); i < 1000; i += 2 {
+		if found, err := tree.Delete(i); err != nil || !found {
+			t.Fatalf("Delete(%d): found=%v, err=%v", i, found, err)
+		}
+	}
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tree = openTree(t, path, 128)
+	defer tree.Close()
+	for i := int64(0); i < 1000; i++ {
+		_, found := mustSearch(t, tree, i)
+		if found != (i%2 == 1) {
+			t.Fatalf("key %d: found=%v", i, found)
+		}
+	}
+	checkInvariants(t, tree)
+}
+
+func TestDiskBTree_RandomInsertDelete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	tree := openTree(t, path, 128, WithBufferPages(4)) // force constant eviction and checkpoints
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	expected := make(map[int64]int64)
+	for i := 0; i < 5000; i++ {
+		k := rng.Int64N(500)
+		if rng.IntN(3) == 0 {
+			found, err := tree.Delete(k)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := expected[k]; found != ok {
+				t.Fatalf("Delete(%d) = %v, want %v", k, found, ok)
+			}
+			delete(expected, k)
+		} else {
+			if err := tree.Insert(k, int64(i)); err != nil {
+				t.Fatal(err)
+			}
+			expected[k] = int64(i)
+		}
+	}
+	checkInvariants(t, tree)
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tree = openTree(t, path, 128)
+	defer tree.Close()
+	for k := int64(0); k < 500; k++ {
+		v, found := mustSearch(t, tree, k)
+		want, ok := expected[k]
+		if found != ok || v != want {
+			t.Fatalf("key %d: found=%v, val=%v; want %v, %v", k, found, v, ok, want)
+		}
+	}
+}
+
+func TestDiskBTree_ReusesFreedPages(t *testing.T) {
+	tree := openTree(t, filepath.Join(t.TempDir(), "tree.db"), 128)
+	defer tree.Close()
+
+	for i := int64(0); i < 500; i++ {
+		mustInsert(t, tree, i, i)
+	}
+	pages := tree.meta.pageCount
+	for i := int64(0); i < 500; i++ {
+		mustDelete(t, tree, i)
+	}
+	if tree.meta.root != 0 {
+		t.Fatalf("expected empty tree, root is page %d", tree.meta.root)
+	}
+	for i := int64(0); i < 500; i++ {
+		mustInsert(t, tree, i, i)
+	}
+	if tree.meta.pageCount != pages {
+		t.Errorf("expected freed pages to be reused (%d pages), got %d", pages, tree.meta.pageCount)
+	}
+}
+
+func TestDiskBTree_DetectsCorruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	tree := openTree(t, path, 128)
+	for i := int64(0); i < 100; i++ {
+		mustInsert(t, tree, i, i)
+	}
+	root := tree.meta.root
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Flip a byte inside the root page
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1)
+	off := int64(root)*128 + headerSize
+	file.ReadAt(buf, off)
+	buf[0] ^= 0xff
+	file.WriteAt(buf, off)
+	file.Close()
+
+	tree = openTree(t, path, 128)
+	defer tree.Close()
+	if _, _, err := tree.Search(50); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestDiskBTree_PageSizeMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	tree := openTree(t, path, 128)
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(path, 256); err == nil {
+		t.Error("expected error opening with a different page size")
+	}
+	if _, err := Open(path, 16); err == nil {
+		t.Error("expected error for page size below minimum")
+	}
+}
+
+// checkInvariants verifies ordering, occupancy bounds and uniform leaf depth.
+func checkInvariants(t *testing.T, tree *BTree) {
+	t.Helper()
+	defer tree.pool.release()
+	if tree.meta.root == 0 {
+		return
+	}
+
+	leafDepth := -1
+	var walk func(id uint32, depth int, lo, hi *int64)
+	walk = func(id uint32, depth int, lo, hi *int64) {
+		n, err := tree.pool.get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != tree.meta.root && len(n.entries) < tree.minEntries() {
+			t.Fatalf("page %d has %d entries, below minimum %d", id, len(n.entries), tree.minEntries())
+		}
+		if len(n.entries) > tree.maxEntries() || len(n.entries) == 0 {
+			t.Fatalf("page %d has %d entries, outside [1, %d]", id, len(n.entries), tree.maxEntries())
+		}
+		for i, e := range n.entries {
+			if (i > 0 && n.entries[i-1].key >= e.key) || (lo != nil && e.key <= *lo) || (hi != nil && e.key >= *hi) {
+				t.Fatalf("page %d is out of order", id)
+			}
+		}
+
+		if n.leaf() {
+			if leafDepth < 0 {
+				leafDepth = depth
+			} else if depth != leafDepth {
+				t.Fatalf("leaf at depth %d, expected %d", depth, leafDepth)
+			}
+			return
+		}
+		if len(n.children) != len(n.entries)+1 {
+			t.Fatalf("page %d has %d children, expected %d", id, len(n.children), len(n.entries)+1)
+		}
+		for i, child := range n.children {
+			childLo, childHi := lo, hi
+			if i > 0 {
+				childLo = &n.entries[i-1].key
+			}
+			if i < len(n.entries) {
+				childHi = &n.entries[i].key
+			}
+			walk(child, depth+1, childLo, childHi)
+		}
+	}
+	walk(tree.meta.root, 0, nil, nil)
+}
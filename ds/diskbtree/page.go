@@ -0,0 +1,185 @@
+package diskbtree
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// Every page starts with a CRC-32C of the rest of the page followed by its kind.
+//
+//	meta:     crc u32 | kind u8 | pad [3] | magic u32 | version u32 | pageSize u32 | root u32 | pageCount u32 | freeHead u32
+//	leaf:     crc u32 | kind u8 | pad u8 | count u16 | count * (key i64, value i64)
+//	internal: crc u32 | kind u8 | pad u8 | count u16 | count * (key i64, value i64) | (count+1) * child u32
+//	free:     crc u32 | kind u8 | pad [3] | next u32
+const (
+	pageMeta byte = iota + 1
+	pageLeaf
+	pageInternal
+	pageFree
+)
+
+const (
+	magic         = 0x45525442 // "BTRE"
+	formatVersion = 1
+
+	headerSize = 8
+	entrySize  = 16
+	childSize  = 4
+
+	MinPageSize = 64
+	MaxPageSize = 1 << 16
+)
+
+var (
+	// ErrCorrupt is returned when a page fails its checksum or does not decode.
+	ErrCorrupt = errors.New("diskbtree: corrupt page")
+
+	castagnoli = crc32.MakeTable(crc32.Castagnoli)
+)
+
+type meta struct {
+	pageSize  uint32
+	root      uint32 // 0 when the tree is empty; page 0 is always the meta page
+	pageCount uint32
+	freeHead  uint32 // 0 when there are no free pages
+}
+
+type entry struct {
+	key   int64
+	value int64
+}
+
+type node struct {
+	id       uint32
+	entries  []entry
+	children []uint32
+
+	dirty bool
+	pins  int
+}
+
+func (n *node) leaf() bool {
+	return len(n.children) == 0
+}
+
+// maxEntries is how many entries fit in an internal node page, which is
+// also the limit used for leaves so every node has the same order.
+func maxEntries(pageSize int) int {
+	return (pageSize - headerSize - childSize) / (entrySize + childSize)
+}
+
+func seal(buf []byte, kind byte) {
+	buf[4] = kind
+	binary.LittleEndian.PutUint32(buf[0:4], crc32.Checksum(buf[4:], castagnoli))
+}
+
+func verify(id uint32, buf []byte, kind byte) error {
+	if binary.LittleEndian.Uint32(buf[0:4]) != crc32.Checksum(buf[4:], castagnoli) {
+		return fmt.Errorf("%w: page %d: checksum mismatch", ErrCorrupt, id)
+	}
+	if kind != 0 && buf[4] != kind {
+		return fmt.Errorf("%w: page %d: unexpected kind %d", ErrCorrupt, id, buf[4])
+	}
+	return nil
+}
+
+func encodeMeta(buf []byte, m meta) {
+	clear(buf)
+	binary.LittleEndian.PutUint32(buf[8:], magic)
+	binary.LittleEndian.PutUint32(buf[12:], formatVersion)
+	binary.LittleEndian.PutUint32(buf[16:], m.pageSize)
+	binary.LittleEndian.PutUint32(buf[20:], m.root)
+	binary.LittleEndian.PutUint32(buf[24:], m.pageCount)
+	binary.LittleEndian.PutUint32(buf[28:], m.freeHead)
+	seal(buf, pageMeta)
+}
+
+func decodeMeta(buf []byte) (meta, error) {
+	if err := verify(0, buf, pageMeta); err != nil {
+		return meta{}, err
+	}
+	if binary.LittleEndian.Uint32(buf[8:]) != magic {
+		return meta{}, fmt.Errorf("%w: bad magic", ErrCorrupt)
+	}
+	if v := binary.LittleEndian.Uint32(buf[12:]); v != formatVersion {
+		return meta{}, fmt.Errorf("diskbtree: unsupported format version %d", v)
+	}
+	return meta{
+		pageSize:  binary.LittleEndian.Uint32(buf[16:]),
+		root:      binary.LittleEndian.Uint32(buf[20:]),
+		pageCount: binary.LittleEndian.Uint32(buf[24:]),
+		freeHead:  binary.LittleEndian.Uint32(buf[28:]),
+	}, nil
+}
+
+func encodeNode(buf []byte, n *node) {
+	clear(buf)
+	binary.LittleEndian.PutUint16(buf[6:], uint16(len(n.entries)))
+
+	off := headerSize
+	for _, e := range n.entries {
+		binary.LittleEndian.PutUint64(buf[off:], uint64(e.key))
+		binary.LittleEndian.PutUint64(buf[off+8:], uint64(e.value))
+		off += entrySize
+	}
+
+	kind := pageLeaf
+	if !n.leaf() {
+		kind = pageInternal
+		for _, c := range n.children {
+			binary.LittleEndian.PutUint32(buf[off:], c)
+			off += childSize
+		}
+	}
+	seal(buf, kind)
+}
+
+func decodeNode(id uint32, buf []byte) (*node, error) {
+	if err := verify(id, buf, 0); err != nil {
+		return nil, err
+	}
+	kind := buf[4]
+	if kind != pageLeaf && kind != pageInternal {
+		return nil, fmt.Errorf("%w: page %d: unexpected kind %d", ErrCorrupt, id, kind)
+	}
+
+	count := int(binary.LittleEndian.Uint16(buf[6:]))
+	if count > maxEntries(len(buf)) {
+		return nil, fmt.Errorf("%w: page %d: %d entries", ErrCorrupt, id, count)
+	}
+
+	n := &node{
+		id:      id,
+		entries: make([]entry, count),
+	}
+	off := headerSize
+	for i := range n.entries {
+		n.entries[i].key = int64(binary.LittleEndian.Uint64(buf[off:]))
+		n.entries[i].value = int64(binary.LittleEndian.Uint64(buf[off+8:]))
+		off += entrySize
+	}
+
+	if kind == pageInternal {
+		n.children = make([]uint32, count+1)
+		for i := range n.children {
+			n.children[i] = binary.LittleEndian.Uint32(buf[off:])
+			off += childSize
+		}
+	}
+	return n, nil
+}
+
+func encodeFree(buf []byte, next uint32) {
+	clear(buf)
+	binary.LittleEndian.PutUint32(buf[8:], next)
+	seal(buf, pageFree)
+}
+
+func decodeFree(id uint32, buf []byte) (uint32, error) {
+	if err := verify(id, buf, pageFree); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf[8:]), nil
+}
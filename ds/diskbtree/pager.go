@@ -0,0 +1,116 @@
+package diskbtree
+
+import (
+	"container/list"
+	"os"
+)
+
+// pager reads and writes fixed-size pages at offset id*pageSize.
+type pager struct {
+	file     *os.File
+	pageSize int
+}
+
+func (p *pager) read(id uint32, buf []byte) error {
+	_, err := p.file.ReadAt(buf, int64(id)*int64(p.pageSize))
+	return err
+}
+
+func (p *pager) write(id uint32, buf []byte) error {
+	_, err := p.file.WriteAt(buf, int64(id)*int64(p.pageSize))
+	return err
+}
+
+// bufferPool caches decoded nodes in LRU order. Nodes fetched during an
+// operation stay pinned until release. Dirty nodes are never written back
+// on eviction (no-steal): they stay cached until a checkpoint writes them,
+// so the data file only ever holds checkpointed state.
+type bufferPool struct {
+	pager    *pager
+	capacity int
+
+	// lruList front = most recently used. Stores *node.
+	lruList *list.List
+	frames  map[uint32]*list.Element
+	pinned  []*node
+
+	buf []byte
+}
+
+func newBufferPool(p *pager, capacity int) *bufferPool {
+	return &bufferPool{
+		pager:    p,
+		capacity: capacity,
+		lruList:  list.New(),
+		frames:   make(map[uint32]*list.Element),
+		buf:      make([]byte, p.pageSize),
+	}
+}
+
+func (bp *bufferPool) get(id uint32) (*node, error) {
+	if el, ok := bp.frames[id]; ok {
+		bp.lruList.MoveToFront(el)
+		return bp.pin(el.Value.(*node)), nil
+	}
+
+	if err := bp.pager.read(id, bp.buf); err != nil {
+		return nil, err
+	}
+	n, err := decodeNode(id, bp.buf)
+	if err != nil {
+		return nil, err
+	}
+	bp.frames[id] = bp.lruList.PushFront(n)
+	return bp.pin(n), nil
+}
+
+// add caches a freshly allocated node.
+func (bp *bufferPool) add(n *node) *node {
+	n.dirty = true
+	bp.frames[n.id] = bp.lruList.PushFront(n)
+	return bp.pin(n)
+}
+
+// drop forgets a node without writing it back, for pages being freed.
+func (bp *bufferPool) drop(id uint32) {
+	if el, ok := bp.frames[id]; ok {
+		bp.lruList.Remove(el)
+		delete(bp.frames, id)
+	}
+}
+
+func (bp *bufferPool) pin(n *node) *node {
+	n.pins++
+	bp.pinned = append(bp.pinned, n)
+	return n
+}
+
+// release unpins every node fetched since the last release and evicts
+// clean, least recently used nodes until the pool is back within capacity.
+func (bp *bufferPool) release() {
+	for _, n := range bp.pinned {
+		n.pins--
+	}
+	clear(bp.pinned)
+	bp.pinned = bp.pinned[:0]
+
+	for el := bp.lruList.Back(); el != nil && bp.lruList.Len() > bp.capacity; {
+		prev := el.Prev()
+		n := el.Value.(*node)
+		if n.pins == 0 && !n.dirty {
+			bp.lruList.Remove(el)
+			delete(bp.frames, n.id)
+		}
+		el = prev
+	}
+}
+
+func (bp *bufferPool) dirty() []*node {
+	var nodes []*node
+	for el := bp.lruList.Front(); el != nil; el = el.Next() {
+		if n := el.Value.(*node); n.dirty {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
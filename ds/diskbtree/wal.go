@@ -0,0 +1,179 @@
+package diskbtree
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+	"slices"
+)
+
+// Each log record is
+//
+//	crc u32 | length u32 | type u8 | payload [length]
+//
+// where crc covers type and payload. Replay stops at the first record that
+// is incomplete or fails its checksum: that is a torn write from a crash.
+const (
+	recInsert byte = iota + 1 // key i64 | value i64
+	recDelete                 // key i64
+	recPage                   // id u32 | page image
+	recCommit                 // ends a checkpoint's page images
+)
+
+const recHeaderSize = 9
+
+// SyncPolicy controls when the write-ahead log is flushed to stable storage.
+type SyncPolicy int
+
+const (
+	// SyncAlways fsyncs the log after every Insert and Delete, so an
+	// acknowledged write survives a crash.
+	SyncAlways SyncPolicy = iota
+	// SyncOnCheckpoint leaves log writes to the OS and only fsyncs on
+	// Sync, checkpoints and Close. A crash may lose recent writes but the
+	// tree is still recovered to a consistent state.
+	SyncOnCheckpoint
+)
+
+type record struct {
+	typ   byte
+	key   int64
+	value int64
+	id    uint32
+	page  []byte
+}
+
+type wal struct {
+	file   *os.File
+	policy SyncPolicy
+	size   int64
+
+	// records counts Insert and Delete records since the last checkpoint
+	records int
+
+	buf []byte
+}
+
+func (w *wal) appendOp(typ byte, key, value int64) error {
+	var payload [16]byte
+	binary.LittleEndian.PutUint64(payload[:], uint64(key))
+	binary.LittleEndian.PutUint64(payload[8:], uint64(value))
+	n := len(payload)
+	if typ == recDelete {
+		n = 8
+	}
+	if err := w.append(typ, payload[:n]); err != nil {
+		return err
+	}
+
+	w.records++
+	if w.policy == SyncAlways {
+		return w.file.Sync()
+	}
+	return nil
+}
+
+func (w *wal) appendPage(id uint32, page []byte) error {
+	payload := make([]byte, 4+len(page))
+	binary.LittleEndian.PutUint32(payload, id)
+	copy(payload[4:], page)
+	return w.append(recPage, payload)
+}
+
+func (w *wal) append(typ byte, payload []byte) error {
+	rec := slices.Grow(w.buf[:0], recHeaderSize+len(payload))[:recHeaderSize]
+	rec[8] = typ
+	rec = append(rec, payload...)
+	binary.LittleEndian.PutUint32(rec[4:], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(rec[0:], crc32.Checksum(rec[8:], castagnoli))
+
+	if _, err := w.file.WriteAt(rec, w.size); err != nil {
+		return err
+	}
+	w.size += int64(len(rec))
+	w.buf = rec[:0]
+	return nil
+}
+
+func (w *wal) sync() error {
+	return w.file.Sync()
+}
+
+// truncate empties the log once a checkpoint has reached the data file.
+func (w *wal) truncate() error {
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+	w.size = 0
+	w.records = 0
+	return w.file.Sync()
+}
+
+// read returns the records in the longest valid prefix of the log and
+// discards anything after it.
+func (w *wal) read(pageSize int) ([]record, error) {
+	data, err := io.ReadAll(io.NewSectionReader(w.file, 0, 1<<62))
+	if err != nil {
+		return nil, err
+	}
+
+	var records []record
+	off := 0
+	for len(data)-off >= recHeaderSize {
+		length := int(binary.LittleEndian.Uint32(data[off+4:]))
+		end := off + recHeaderSize + length
+		if length > 4+pageSize || end > len(data) {
+			break
+		}
+		if binary.LittleEndian.Uint32(data[off:]) != crc32.Checksum(data[off+8:end], castagnoli) {
+			break
+		}
+
+		rec, ok := decodeRecord(data[off+8], data[off+recHeaderSize:end], pageSize)
+		if !ok {
+			break
+		}
+		records = append(records, rec)
+		off = end
+	}
+
+	w.size = int64(off)
+	if off < len(data) {
+		// Torn tail from a crash: drop it so new records follow valid ones
+		if err := w.file.Truncate(w.size); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+func decodeRecord(typ byte, payload []byte, pageSize int) (record, bool) {
+	rec := record{typ: typ}
+	switch typ {
+	case recInsert:
+		if len(payload) != 16 {
+			return rec, false
+		}
+		rec.key = int64(binary.LittleEndian.Uint64(payload))
+		rec.value = int64(binary.LittleEndian.Uint64(payload[8:]))
+	case recDelete:
+		if len(payload) != 8 {
+			return rec, false
+		}
+		rec.key = int64(binary.LittleEndian.Uint64(payload))
+	case recPage:
+		if len(payload) != 4+pageSize {
+			return rec, false
+		}
+		rec.id = binary.LittleEndian.Uint32(payload)
+		rec.page = payload[4:]
+	case recCommit:
+		if len(payload) != 0 {
+			return rec, false
+		}
+	default:
+		return rec, false
+	}
+	return rec, true
+}
@@ -0,0 +1,395 @@
+package diskbtree
+
+import (
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// crash abandons the tree without checkpointing, as if the process died.
+func crash(t *testing.T, tree *BTree) {
+	t.Helper()
+	tree.pager.file.Close()
+	tree.wal.file.Close()
+}
+
+func checkContents(t *testing.T, tree *BTree, expected map[int64]int64, keys int64) {
+	t.Helper()
+	for k := int64(0); k < keys; k++ {
+		v, found := mustSearch(t, tree, k)
+		want, ok := expected[k]
+		if found != ok || v != want {
+			t.Fatalf("key %d: found=%v, val=%v; want %v, %v", k, found, v, ok, want)
+		}
+	}
+	checkInvariants(t, tree)
+}
+
+func TestWAL_RecoversAfterCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	tree := openTree(t, path, 128)
+
+	expected := make(map[int64]int64)
+	for i := int64(0); i < 300; i++ {
+		mustInsert(t, tree, i, i*3)
+		expected[i] = i * 3
+	}
+	for i := int64(0); i < 300; i += 3 {
+		mustDelete(t, tree, i)
+		delete(expected, i)
+	}
+	crash(t, tree)
+
+	tree = openTree(t, path, 128)
+	defer tree.Close()
+	checkContents(t, tree, expected, 300)
+
+	// Recovery checkpointed, so the log is empty again
+	if tree.wal.size != 0 {
+		t.Errorf("expected empty log after recovery, got %d bytes", tree.wal.size)
+	}
+}
+
+func TestWAL_TruncatedAtEveryOffset(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tree.db")
+
+	// Checkpointed base state
+	base := make(map[int64]int64)
+	tree := openTree(t, path, 128)
+	for i := int64(0); i < 40; i++ {
+		mustInsert(t, tree, i, i)
+		base[i] = i
+	}
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Logged but never checkpointed; remember where each operation ends
+	type op struct {
+		del        bool
+		key, value int64
+		end        int64
+	}
+	var ops []op
+	rng := rand.New(rand.NewPCG(3, 4))
+	tree = openTree(t, path, 128, WithCheckpointEvery(1<<20), WithBufferPages(1<<20))
+	for i := int64(0); i < 60; i++ {
+		o := op{key: rng.Int64N(60), value: 1000 + i}
+		if rng.IntN(3) == 0 {
+			o.del = true
+			mustDelete(t, tree, o.key)
+		} else {
+			mustInsert(t, tree, o.key, o.value)
+		}
+		o.end = tree.wal.size
+		ops = append(ops, o)
+	}
+	crash(t, tree)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log, err := os.ReadFile(path + ".wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for cut := 0; cut <= len(log); cut++ {
+		casePath := filepath.Join(dir, "case.db")
+		if err := os.WriteFile(casePath, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(casePath+".wal", log[:cut], 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := make(map[int64]int64, len(base))
+		for k, v := range base {
+			expected[k] = v
+		}
+		for _, o := range ops {
+			if o.end > int64(cut) {
+				break
+			}
+			if o.del {
+				delete(expected, o.key)
+			} else {
+				expected[o.key] = o.value
+			}
+		}
+
+		tree := openTree(t, casePath, 128, WithSyncPolicy(SyncOnCheckpoint))
+		checkContents(t, tree, expected, 60)
+		if err := tree.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWAL_TruncatedAroundCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tree.db")
+
+	base := make(map[int64]int64)
+	tree := openTree(t, path, 128)
+	for i := int64(0); i < 40; i++ {
+		mustInsert(t, tree, i, i)
+		base[i] = i
+	}
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Operations, a checkpoint that reaches the log but not the data file,
+	// then more operations. Key 0 is rewritten first, so replaying page
+	// images as operations would show up as key 0 going missing.
+	type op struct {
+		del        bool
+		key, value int64
+		end        int64
+	}
+	var ops []op
+	rng := rand.New(rand.NewPCG(5, 6))
+	tree = openTree(t, path, 128, WithCheckpointEvery(1<<20), WithBufferPages(1<<20))
+	logOps := func(n int64) {
+		for i := int64(0); i < n; i++ {
+			o := op{key: rng.Int64N(60), value: 1000 + int64(len(ops))}
+			if i == 0 {
+				o.key = 0
+			}
+			if i > 0 && rng.IntN(3) == 0 {
+				o.del = true
+				mustDelete(t, tree, o.key)
+			} else {
+				mustInsert(t, tree, o.key, o.value)
+			}
+			o.end = tree.wal.size
+			ops = append(ops, o)
+		}
+	}
+	logOps(20)
+	if _, err := tree.logCheckpoint(); err != nil {
+		t.Fatal(err)
+	}
+	committed := tree.wal.size
+	logOps(20)
+	crash(t, tree)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log, err := os.ReadFile(path + ".wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if committed <= ops[19].end || committed >= int64(len(log)) {
+		t.Fatalf("expected the checkpoint between the two runs of operations")
+	}
+
+	for cut := 0; cut <= len(log); cut++ {
+		casePath := filepath.Join(dir, "case.db")
+		if err := os.WriteFile(casePath, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(casePath+".wal", log[:cut], 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := make(map[int64]int64, len(base))
+		for k, v := range base {
+			expected[k] = v
+		}
+		for _, o := range ops {
+			if o.end > int64(cut) {
+				break
+			}
+			if o.del {
+				delete(expected, o.key)
+			} else {
+				expected[o.key] = o.value
+			}
+		}
+
+		tree := openTree(t, casePath, 128, WithSyncPolicy(SyncOnCheckpoint))
+		checkContents(t, tree, expected, 60)
+		if err := tree.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWAL_CrashDuringCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	tree := openTree(t, path, 128, WithCheckpointEvery(1<<20), WithBufferPages(1<<20))
+
+	expected := make(map[int64]int64)
+	for i := int64(0); i < 200; i++ {
+		mustInsert(t, tree, i, -i)
+		expected[i] = -i
+	}
+
+	// The checkpoint reaches the log, but every page write to the data
+	// file is torn before the crash
+	images, err := tree.logCheckpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	garbage := make([]byte, 128)
+	for i := range garbage {
+		garbage[i] = 0xA5
+	}
+	for _, img := range images {
+		if err := tree.pager.write(img.id, garbage); err != nil {
+			t.Fatal(err)
+		}
+	}
+	crash(t, tree)
+
+	tree = openTree(t, path, 128)
+	defer tree.Close()
+	checkContents(t, tree, expected, 200)
+}
+
+// fillForCheckpoint inserts and deletes enough keys to split and merge
+// nodes, so a checkpoint has node images and freed pages to write.
+func fillForCheckpoint(t *testing.T, tree *BTree, expected map[int64]int64) {
+	t.Helper()
+	for i := int64(0); i < 300; i++ {
+		if err := tree.Insert(i, i+7); err != nil {
+			t.Fatal(err)
+		}
+		expected[i] = i + 7
+	}
+	for i := int64(50); i < 250; i++ {
+		if _, err := tree.Delete(i); err != nil {
+			t.Fatal(err)
+		}
+		delete(expected, i)
+	}
+}
+
+// readOnly swaps *file for a read-only handle on the same file, so writes
+// to it fail, and returns a function that swaps the original back.
+func readOnly(t *testing.T, file **os.File) func() {
+	t.Helper()
+	ro, err := os.Open((*file).Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := *file
+	*file = ro
+	return func() {
+		*file = orig
+		ro.Close()
+	}
+}
+
+func TestWAL_FailedLogWriteKeepsData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	tree := openTree(t, path, 128, WithCheckpointEvery(1<<20), WithBufferPages(1<<20))
+	expected := make(map[int64]int64)
+	fillForCheckpoint(t, tree, expected)
+	if len(tree.pendingFree) == 0 {
+		t.Fatal("expected deletes to free pages")
+	}
+
+	restore := readOnly(t, &tree.wal.file)
+	if err := tree.Checkpoint(); err == nil {
+		t.Fatal("expected checkpoint to fail writing the log")
+	}
+	restore()
+
+	// The failed checkpoint left its pages dirty, so the next one, made
+	// by Close, still writes them
+	if err := tree.Insert(1000, 1); err != nil {
+		t.Fatal(err)
+	}
+	expected[1000] = 1
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tree = openTree(t, path, 128)
+	defer tree.Close()
+	checkContents(t, tree, expected, 1001)
+}
+
+func TestWAL_FailedDataWriteKeepsData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	tree := openTree(t, path, 128, WithCheckpointEvery(1<<20), WithBufferPages(1<<20))
+	expected := make(map[int64]int64)
+	fillForCheckpoint(t, tree, expected)
+
+	restore := readOnly(t, &tree.pager.file)
+	if err := tree.Checkpoint(); err == nil {
+		t.Fatal("expected checkpoint to fail writing the data file")
+	}
+	restore()
+
+	// The log holds the only copy of the checkpoint, so nothing may
+	// truncate it until the tree is reopened
+	if err := tree.Insert(1000, 1); err != nil {
+		t.Fatal(err)
+	}
+	expected[1000] = 1
+	if err := tree.Close(); err == nil {
+		t.Fatal("expected Close to report the unapplied checkpoint")
+	}
+
+	tree = openTree(t, path, 128)
+	defer tree.Close()
+	checkContents(t, tree, expected, 1001)
+}
+
+func TestWAL_CheckpointTruncatesLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	tree := openTree(t, path, 4096, WithCheckpointEvery(10), WithSyncPolicy(SyncOnCheckpoint))
+	defer tree.Close()
+
+	for i := int64(0); i < 9; i++ {
+		mustInsert(t, tree, i, i)
+	}
+	if tree.wal.size == 0 || tree.wal.records != 9 {
+		t.Fatalf("expected 9 logged records, got %d (%d bytes)", tree.wal.records, tree.wal.size)
+	}
+
+	// The 10th operation triggers a checkpoint
+	mustInsert(t, tree, 9, 9)
+	if tree.wal.size != 0 {
+		t.Errorf("expected log to be truncated, got %d bytes", tree.wal.size)
+	}
+	info, err := os.Stat(path + ".wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("expected empty log file, got %d bytes", info.Size())
+	}
+
+	if err := tree.Sync(); err != nil {
+		t.Errorf("Sync: %v", err)
+	}
+}
+
+func TestWAL_SmallBufferPoolCheckpoints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	tree := openTree(t, path, 128, WithBufferPages(8), WithSyncPolicy(SyncOnCheckpoint))
+
+	expected := make(map[int64]int64)
+	for i := int64(0); i < 500; i++ {
+		mustInsert(t, tree, i, i)
+		expected[i] = i
+		if n := len(tree.pool.dirty()); n > 8 {
+			t.Fatalf("expected at most 8 dirty pages after an operation, got %d", n)
+		}
+	}
+	crash(t, tree)
+
+	tree = openTree(t, path, 128)
+	defer tree.Close()
+	checkContents(t, tree, expected, 500)
+}
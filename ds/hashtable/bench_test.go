@@ -2,6 +2,7 @@ package hashtable
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 )
 
@@ -70,3 +71,272 @@ func BenchmarkNativeMap_Get(b *testing.B) {
 	}
 }
 
+func BenchmarkHashTable_GetInt(b *testing.B) {
+	numItems := 10000
+	ht := New[int, int](numItems)
+	for i := 0; i < numItems; i++ {
+		ht.Put(i, i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ht.Get(i % numItems)
+	}
+}
+
+func BenchmarkHashTable_GetIntHasher(b *testing.B) {
+	numItems := 10000
+	ht := New[int, int](numItems, WithHasher(NewIntegerHasher[int]()))
+	for i := 0; i < numItems; i++ {
+		ht.Put(i, i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ht.Get(i % numItems)
+	}
+}
+
+func BenchmarkHashTable_GetStringHasher(b *testing.B) {
+	numItems := 10000
+	ht := New[string, int](numItems, WithHasher(NewStringHasher[string]()))
+	keys := make([]string, numItems)
+	for i := 0; i < numItems; i++ {
+		keys[i] = fmt.Sprintf("key-%d", i)
+		ht.Put(keys[i], i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ht.Get(keys[i%numItems])
+	}
+}
+
+// benchChurn fills a table and then replaces keys with deletes and
+// inserts, reporting throughput and the average successful probe length.
+func benchChurn(b *testing.B, opts ...Option) {
+	const numItems = 5000
+	ht := New[int, int](2*numItems, opts...)
+	for i := 0; i < numItems; i++ {
+		ht.Put(i, i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ht.Delete(i)
+		ht.Put(i+numItems, i)
+		ht.Get(i + numItems/2)
+	}
+	b.StopTimer()
+
+	probes := 0
+	for i := range ht.data {
+		if ht.data[i].occupied {
+			probes += ht.probeDistance(i) + 1
+		}
+	}
+	b.ReportMetric(float64(probes)/float64(ht.Len()), "probes/key")
+}
+
+func BenchmarkHashTable_ChurnLinear(b *testing.B) {
+	benchChurn(b)
+}
+
+func BenchmarkHashTable_ChurnRobinHood(b *testing.B) {
+	benchChurn(b, WithRobinHood())
+}
+
+// benchParallel runs a parallel workload on keys [0, numKeys) where
+// writePct percent of operations are stores and the rest are loads.
+func benchParallel(b *testing.B, writePct int, load func(int), store func(int)) {
+	const numKeys = 10000
+	for k := 0; k < numKeys; k++ {
+		store(k)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			k := (i * 7919) % numKeys
+			if i%100 < writePct {
+				store(k)
+			} else {
+				load(k)
+			}
+			i++
+		}
+	})
+}
+
+func benchConcurrentHashTable(b *testing.B, writePct int) {
+	c := NewConcurrent[int, int](0)
+	benchParallel(b, writePct, func(k int) { c.Get(k) }, func(k int) { c.Put(k, k) })
+}
+
+func benchSyncMap(b *testing.B, writePct int) {
+	var m sync.Map
+	benchParallel(b, writePct, func(k int) { m.Load(k) }, func(k int) { m.Store(k, k) })
+}
+
+func benchMutexMap(b *testing.B, writePct int) {
+	var mu sync.RWMutex
+	m := make(map[int]int)
+	benchParallel(b, writePct,
+		func(k int) {
+			mu.RLock()
+			_ = m[k]
+			mu.RUnlock()
+		},
+		func(k int) {
+			mu.Lock()
+			m[k] = k
+			mu.Unlock()
+		})
+}
+
+func BenchmarkConcurrentHashTable_ReadHeavy(b *testing.B)  { benchConcurrentHashTable(b, 10) }
+func BenchmarkSyncMap_ReadHeavy(b *testing.B)              { benchSyncMap(b, 10) }
+func BenchmarkMutexMap_ReadHeavy(b *testing.B)             { benchMutexMap(b, 10) }
+func BenchmarkConcurrentHashTable_WriteHeavy(b *testing.B) { benchConcurrentHashTable(b, 50) }
+func BenchmarkSyncMap_WriteHeavy(b *testing.B)             { benchSyncMap(b, 50) }
+func BenchmarkMutexMap_WriteHeavy(b *testing.B)            { benchMutexMap(b, 50) }
+
+func BenchmarkSwissTable_Insert(b *testing.B) {
+	s := NewSwiss[string, int](b.N)
+	keys := make([]string, b.N)
+	for i := 0; i < b.N; i++ {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Put(keys[i], i)
+	}
+}
+
+func BenchmarkSwissTable_Get(b *testing.B) {
+	numItems := 10000
+	s := NewSwiss[string, int](numItems)
+	keys := make([]string, numItems)
+	for i := 0; i < numItems; i++ {
+		keys[i] = fmt.Sprintf("key-%d", i)
+		s.Put(keys[i], i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Get(keys[i%numItems])
+	}
+}
+
+func BenchmarkSwissTable_GetMiss(b *testing.B) {
+	numItems := 10000
+	s := NewSwiss[int, int](numItems)
+	for i := 0; i < numItems; i++ {
+		s.Put(i, i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Get(numItems + i)
+	}
+}
+
+func BenchmarkHashTable_GetMiss(b *testing.B) {
+	numItems := 10000
+	ht := New[int, int](numItems)
+	for i := 0; i < numItems; i++ {
+		ht.Put(i, i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ht.Get(numItems + i)
+	}
+}
+
+func BenchmarkNativeMap_GetMiss(b *testing.B) {
+	numItems := 10000
+	m := make(map[int]int, numItems)
+	for i := 0; i < numItems; i++ {
+		m[i] = i
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m[numItems+i]
+	}
+}
+
+func BenchmarkHashTable_Stats(b *testing.B) {
+	ht := New[int, int](0)
+	for i := 0; i < 100000; i++ {
+		ht.Put(i, i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ht.Stats()
+	}
+}
+
+func BenchmarkHashTable_CountGetPut(b *testing.B) {
+	ht := New[int, int](0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k := i % 1000
+		n, _ := ht.Get(k)
+		ht.Put(k, n+1)
+	}
+}
+
+func BenchmarkHashTable_CountUpdate(b *testing.B) {
+	ht := New[int, int](0)
+	incr := func(n int, _ bool) (int, bool) { return n + 1, true }
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ht.Update(i%1000, incr)
+	}
+}
+
+func BenchmarkCuckooTable_Insert(b *testing.B) {
+	c := NewCuckoo[string, int](b.N)
+	keys := make([]string, b.N)
+	for i := 0; i < b.N; i++ {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Put(keys[i], i)
+	}
+}
+
+func BenchmarkCuckooTable_Get(b *testing.B) {
+	numItems := 10000
+	c := NewCuckoo[string, int](numItems)
+	keys := make([]string, numItems)
+	for i := 0; i < numItems; i++ {
+		keys[i] = fmt.Sprintf("key-%d", i)
+		c.Put(keys[i], i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get(keys[i%numItems])
+	}
+}
+
+func BenchmarkCuckooTable_GetMiss(b *testing.B) {
+	numItems := 10000
+	c := NewCuckoo[int, int](numItems)
+	for i := 0; i < numItems; i++ {
+		c.Put(i, i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get(numItems + i)
+	}
+}
@@ -0,0 +1,160 @@
+package hashtable
+
+import (
+	"math/bits"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// ConcurrentHashTable is a hash table that is safe for concurrent use. Keys
+// are spread over a power-of-two number of shards by the top bits of
+// their hash, and each shard is a HashTable behind its own lock, so
+// operations on different shards never contend.
+type ConcurrentHashTable[K comparable, V any] struct {
+	shards []shard[K, V]
+	hasher Hasher[K]
+	// shift turns a hash into a shard index
+	shift uint
+	size  atomic.Int64
+}
+
+type shard[K comparable, V any] struct {
+	mu    sync.RWMutex
+	table *HashTable[K, V]
+	// Keep each shard on its own cache line
+	_ [32]byte
+}
+
+// NewConcurrent returns an empty table with the given number of shards,
+// rounded up to a power of two. If shards is 0 or less it defaults to four
+// per CPU. opts configure every shard as they would a HashTable.
+func NewConcurrent[K comparable, V any](shards int, opts ...Option) *ConcurrentHashTable[K, V] {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	// Shards share the table's hasher: the top bits pick the shard, and
+	// the shard's own probing depends on all of them
+	hasher := resolveHasher[K](cfg)
+	opts = append(slices.Clip(opts), WithHasher(hasher))
+
+	if shards <= 0 {
+		shards = 4 * runtime.GOMAXPROCS(0)
+	}
+	shards = roundUpPow2(shards)
+
+	c := &ConcurrentHashTable[K, V]{
+		shards: make([]shard[K, V], shards),
+		hasher: hasher,
+		shift:  uint(64 - bits.TrailingZeros(uint(shards))),
+	}
+	for i := range c.shards {
+		c.shards[i].table = New[K, V](0, opts...)
+	}
+	return c
+}
+
+func (c *ConcurrentHashTable[K, V]) shardFor(k K) *shard[K, V] {
+	return &c.shards[c.hasher.Hash(k)>>c.shift]
+}
+
+// Len returns the number of keys. It never blocks.
+func (c *ConcurrentHashTable[K, V]) Len() int {
+	return int(c.size.Load())
+}
+
+func (c *ConcurrentHashTable[K, V]) Get(k K) (V, bool) {
+	s := c.shardFor(k)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.table.Get(k)
+}
+
+func (c *ConcurrentHashTable[K, V]) Put(k K, v V) {
+	s := c.shardFor(k)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.table.Len()
+	s.table.Put(k, v)
+	c.size.Add(int64(s.table.Len() - n))
+}
+
+func (c *ConcurrentHashTable[K, V]) Delete(k K) bool {
+	s := c.shardFor(k)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.table.Delete(k) {
+		return false
+	}
+	c.size.Add(-1)
+	return true
+}
+
+// LoadOrStore returns the value stored under k with true if there is
+// one. Otherwise it stores v and returns it with false.
+func (c *ConcurrentHashTable[K, V]) LoadOrStore(k K, v V) (V, bool) {
+	s := c.shardFor(k)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if actual, ok := s.table.Get(k); ok {
+		return actual, true
+	}
+	s.table.Put(k, v)
+	c.size.Add(1)
+	return v, false
+}
+
+// Compute atomically updates the value under k. fn receives the current
+// value and whether k is present, and returns the new value and whether
+// to keep it; returning false deletes k (or leaves it absent). Compute
+// returns the new value and whether k is now present.
+//
+// fn runs with k's shard locked, so it must not use the table.
+func (c *ConcurrentHashTable[K, V]) Compute(k K, fn func(old V, loaded bool) (V, bool)) (V, bool) {
+	s := c.shardFor(k)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, loaded := s.table.Get(k)
+	v, keep := fn(old, loaded)
+	switch {
+	case keep:
+		s.table.Put(k, v)
+		if !loaded {
+			c.size.Add(1)
+		}
+	case loaded:
+		s.table.Delete(k)
+		c.size.Add(-1)
+	}
+	return v, keep
+}
+
+// Range calls fn for each key and value until fn returns false. Each shard
+// is copied under its lock and then visited with no lock held, so fn may
+// use the table; it sees every shard as it was when that shard was copied,
+// not a snapshot of the whole table.
+func (c *ConcurrentHashTable[K, V]) Range(fn func(k K, v V) bool) {
+	type pair struct {
+		k K
+		v V
+	}
+	var buf []pair
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.RLock()
+		buf = buf[:0]
+		for k, v := range s.table.All() {
+			buf = append(buf, pair{k, v})
+		}
+		s.mu.RUnlock()
+
+		for _, p := range buf {
+			if !fn(p.k, p.v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package hashtable
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestConcurrent_Basic(t *testing.T) {
+	c := NewConcurrent[string, int](0)
+	if len(c.shards)&(len(c.shards)-1) != 0 {
+		t.Errorf("expected a power-of-two shard count, got %d", len(c.shards))
+	}
+
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("a", 3)
+	if v, ok := c.Get("a"); !ok || v != 3 {
+		t.Errorf("expected 3, got %d (found=%v)", v, ok)
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected len 2, got %d", c.Len())
+	}
+
+	if v, loaded := c.LoadOrStore("b", 20); !loaded || v != 2 {
+		t.Errorf("LoadOrStore(b) = %d, %v; want 2, true", v, loaded)
+	}
+	if v, loaded := c.LoadOrStore("c", 30); loaded || v != 30 {
+		t.Errorf("LoadOrStore(c) = %d, %v; want 30, false", v, loaded)
+	}
+
+	if !c.Delete("a") || c.Delete("a") {
+		t.Error("expected exactly one successful Delete(a)")
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected len 2, got %d", c.Len())
+	}
+}
+
+func TestConcurrent_Compute(t *testing.T) {
+	c := NewConcurrent[string, int](4)
+
+	// Insert when absent
+	v, ok := c.Compute("x", func(old int, loaded bool) (int, bool) {
+		if loaded {
+			t.Error("expected x to be absent")
+		}
+		return 1, true
+	})
+	if !ok || v != 1 || c.Len() != 1 {
+		t.Errorf("Compute insert: %d, %v, len %d", v, ok, c.Len())
+	}
+
+	// Update
+	c.Compute("x", func(old int, loaded bool) (int, bool) { return old + 10, true })
+	if v, _ := c.Get("x"); v != 11 {
+		t.Errorf("expected 11, got %d", v)
+	}
+
+	// Delete, and declining to insert
+	c.Compute("x", func(int, bool) (int, bool) { return 0, false })
+	c.Compute("y", func(int, bool) (int, bool) { return 0, false })
+	if c.Len() != 0 {
+		t.Errorf("expected empty table, got len %d", c.Len())
+	}
+}
+
+func TestConcurrent_ParallelCompute(t *testing.T) {
+	c := NewConcurrent[int, int](8)
+	const workers, perWorker, keys = 8, 1000, 50
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				c.Compute(i%keys, func(old int, _ bool) (int, bool) { return old + 1, true })
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	c.Range(func(k, v int) bool {
+		total += v
+		return true
+	})
+	if total != workers*perWorker || c.Len() != keys {
+		t.Errorf("expected total %d over %d keys, got %d over %d", workers*perWorker, keys, total, c.Len())
+	}
+}
+
+func TestConcurrent_LoadOrStoreSingleWinner(t *testing.T) {
+	c := NewConcurrent[string, int](4)
+	var wg sync.WaitGroup
+	wins := make([]bool, 16)
+	for w := range wins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, loaded := c.LoadOrStore("key", w)
+			wins[w] = !loaded
+		}()
+	}
+	wg.Wait()
+
+	n := 0
+	for _, won := range wins {
+		if won {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("expected exactly one store, got %d", n)
+	}
+}
+
+func TestConcurrent_MixedWithRange(t *testing.T) {
+	c := NewConcurrent[int, int](4)
+	// Even keys are stable; writers only touch odd keys
+	for k := 0; k < 1000; k += 2 {
+		c.Put(k, k)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				k := (i*7+w)%1000 | 1
+				if i%2 == 0 {
+					c.Put(k, k)
+				} else {
+					c.Delete(k)
+				}
+				if v, ok := c.Get(k - 1); !ok || v != k-1 {
+					t.Errorf("stable key %d: found=%v, val=%v", k-1, ok, v)
+					return
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for pass := 0; pass < 20; pass++ {
+			stable := 0
+			c.Range(func(k, v int) bool {
+				if k != v {
+					t.Errorf("Range yielded %d=%d", k, v)
+				}
+				if k%2 == 0 {
+					stable++
+				}
+				return true
+			})
+			if stable != 500 {
+				t.Errorf("expected 500 stable keys, got %d", stable)
+			}
+		}
+	}()
+	wg.Wait()
+
+	n := 0
+	c.Range(func(int, int) bool {
+		n++
+		return true
+	})
+	if n != c.Len() {
+		t.Errorf("Range saw %d keys, Len is %d", n, c.Len())
+	}
+}
+
+func TestConcurrent_RangeCanModify(t *testing.T) {
+	c := NewConcurrent[int, int](2)
+	for k := 0; k < 100; k++ {
+		c.Put(k, k)
+	}
+	c.Range(func(k, v int) bool {
+		c.Delete(k)
+		return true
+	})
+	if c.Len() != 0 {
+		t.Errorf("expected Range to delete every key, %d left", c.Len())
+	}
+
+	n := 0
+	c.Put(1, 1)
+	c.Put(2, 2)
+	c.Range(func(int, int) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("expected Range to stop after one key, got %d", n)
+	}
+}
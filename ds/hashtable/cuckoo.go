@@ -0,0 +1,345 @@
+package hashtable
+
+import (
+	"iter"
+	"math/bits"
+	"math/rand/v2"
+)
+
+// CuckooTable is a hash table whose lookups take constant time in the
+// worst case. Each key lives in one of two candidate buckets of four
+// slots, picked by mixing its hash with a per-bucket-choice seed, or else
+// in a small stash, so a lookup never examines more than eight slots and
+// the stash.
+//
+// Inserting into two full buckets evicts an entry to its other bucket,
+// which may evict another, and so on. A chain that runs too long leaves
+// its last entry in the stash, and when the stash is full the table is
+// rebuilt with new seeds, growing if rebuilding at the same size keeps
+// failing. Put panics if no size works, which only happens when the
+// hasher maps more keys to one hash than two buckets and the stash hold.
+type CuckooTable[K comparable, V any] struct {
+	buckets []cuckooBucket[K, V]
+	// mask is len(buckets)-1; the bucket count is a power of two
+	mask   uint64
+	seeds  [cuckooWays]uint64
+	stash  []cuckooEntry[K, V]
+	size   int
+	mods   int
+	hasher Hasher[K]
+}
+
+type cuckooBucket[K comparable, V any] struct {
+	// used has bit i set if slot i holds an entry
+	used   uint8
+	keys   [cuckooBucketSize]K
+	values [cuckooBucketSize]V
+}
+
+type cuckooEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+const (
+	cuckooWays       = 2
+	cuckooBucketSize = 4
+	cuckooStashSize  = 4
+	// cuckooMaxKicks bounds the eviction chain of a single insert
+	cuckooMaxKicks = 256
+	// The table doubles after cuckooRebuilds failed rebuilds at one size
+	cuckooRebuilds = 4
+	// A table with cuckooMaxSpread slots per key that still cannot place
+	// its keys never will
+	cuckooMaxSpread = 64
+)
+
+// NewCuckoo returns an empty CuckooTable with room for at least initialCap
+// keys. Of the options, only WithHasher applies.
+func NewCuckoo[K comparable, V any](initialCap int, opts ...Option) *CuckooTable[K, V] {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	c := &CuckooTable[K, V]{hasher: resolveHasher[K](cfg)}
+	c.init(cuckooBucketsFor(initialCap))
+	return c
+}
+
+func cuckooBucketsFor(n int) int {
+	// Fewest buckets, a power of two and at least two, holding n keys at
+	// 7/8 load
+	slots := (n*8 + 6) / 7
+	return roundUpPow2(max((slots+cuckooBucketSize-1)/cuckooBucketSize, 2))
+}
+
+func (c *CuckooTable[K, V]) init(buckets int) {
+	c.buckets = make([]cuckooBucket[K, V], buckets)
+	c.mask = uint64(buckets - 1)
+	for i := range c.seeds {
+		c.seeds[i] = rand.Uint64()
+	}
+	c.stash = c.stash[:0]
+	c.mods++
+}
+
+func (c *CuckooTable[K, V]) bucketFor(hash uint64, way int) uint64 {
+	return mix64(hash^c.seeds[way]) & c.mask
+}
+
+func (c *CuckooTable[K, V]) Len() int {
+	return c.size
+}
+
+// find returns the bucket and slot holding k, or a nil bucket and k's
+// index in the stash.
+func (c *CuckooTable[K, V]) find(k K) (*cuckooBucket[K, V], int, bool) {
+	hash := c.hasher.Hash(k)
+	for way := range cuckooWays {
+		b := &c.buckets[c.bucketFor(hash, way)]
+		for m := b.used; m != 0; m &= m - 1 {
+			slot := bits.TrailingZeros8(m)
+			if b.keys[slot] == k {
+				return b, slot, true
+			}
+		}
+	}
+	for i := range c.stash {
+		if c.stash[i].key == k {
+			return nil, i, true
+		}
+	}
+	return nil, 0, false
+}
+
+func (c *CuckooTable[K, V]) Get(k K) (V, bool) {
+	b, i, ok := c.find(k)
+	switch {
+	case !ok:
+		var zero V
+		return zero, false
+	case b == nil:
+		return c.stash[i].value, true
+	}
+	return b.values[i], true
+}
+
+func (c *CuckooTable[K, V]) Put(k K, v V) {
+	if b, i, ok := c.find(k); ok {
+		if b == nil {
+			c.stash[i].value = v
+		} else {
+			b.values[i] = v
+		}
+		return
+	}
+
+	c.size++
+	c.mods++
+	if c.size > len(c.buckets)*cuckooBucketSize*7/8 {
+		c.rebuild(len(c.buckets)*2, k, v)
+		return
+	}
+	if k, v, ok := c.insert(k, v); !ok {
+		c.rebuild(len(c.buckets), k, v)
+	}
+}
+
+func (c *CuckooTable[K, V]) Delete(k K) bool {
+	b, i, ok := c.find(k)
+	if !ok {
+		return false
+	}
+	if b == nil {
+		c.unstash(i)
+	} else {
+		var zeroK K
+		var zeroV V
+		b.keys[i] = zeroK
+		b.values[i] = zeroV
+		b.used &^= 1 << i
+		c.drainStash()
+	}
+	c.size--
+	c.mods++
+	return true
+}
+
+// All returns an iterator over the table's key-value pairs in no
+// particular order, with the same rules as HashTable.All.
+func (c *CuckooTable[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		mods := c.mods
+		step := func(k K, v V) bool {
+			if !yield(k, v) {
+				return false
+			}
+			if c.mods != mods {
+				panic("hashtable: table modified during iteration")
+			}
+			return true
+		}
+		for i := range c.buckets {
+			b := &c.buckets[i]
+			for m := b.used; m != 0; m &= m - 1 {
+				slot := bits.TrailingZeros8(m)
+				if !step(b.keys[slot], b.values[slot]) {
+					return
+				}
+			}
+		}
+		for i := 0; i < len(c.stash); i++ {
+			if !step(c.stash[i].key, c.stash[i].value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over the table's keys, with the same rules as All.
+func (c *CuckooTable[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range c.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the table's values, with the same rules
+// as All.
+func (c *CuckooTable[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range c.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// place puts an entry in a free slot of bucket idx if it has one.
+func (c *CuckooTable[K, V]) place(idx uint64, k K, v V) bool {
+	b := &c.buckets[idx]
+	free := ^b.used & (1<<cuckooBucketSize - 1)
+	if free == 0 {
+		return false
+	}
+	slot := bits.TrailingZeros8(free)
+	b.keys[slot] = k
+	b.values[slot] = v
+	b.used |= 1 << slot
+	return true
+}
+
+// insert places an entry whose key is absent. If the eviction chain runs
+// out and the stash is full, it returns the entry left without a slot,
+// which need not be k's, and false.
+func (c *CuckooTable[K, V]) insert(k K, v V) (K, V, bool) {
+	hash := c.hasher.Hash(k)
+	for way := range cuckooWays {
+		if c.place(c.bucketFor(hash, way), k, v) {
+			return k, v, true
+		}
+	}
+
+	// Swap the entry with a random one in a full candidate bucket and try
+	// to move that one to one of its other candidates; a random walk like
+	// this is unlikely to cycle for long
+	idx := c.bucketFor(hash, rand.IntN(cuckooWays))
+	for range cuckooMaxKicks {
+		b := &c.buckets[idx]
+		slot := rand.IntN(cuckooBucketSize)
+		k, b.keys[slot] = b.keys[slot], k
+		v, b.values[slot] = b.values[slot], v
+
+		hash := c.hasher.Hash(k)
+		var alts [cuckooWays]uint64
+		n := 0
+		for way := range cuckooWays {
+			alt := c.bucketFor(hash, way)
+			if alt == idx {
+				continue
+			}
+			if c.place(alt, k, v) {
+				return k, v, true
+			}
+			alts[n] = alt
+			n++
+		}
+		if n > 0 {
+			idx = alts[rand.IntN(n)]
+		}
+	}
+
+	if len(c.stash) < cuckooStashSize {
+		c.stash = append(c.stash, cuckooEntry[K, V]{k, v})
+		return k, v, true
+	}
+	return k, v, false
+}
+
+// rebuild reinserts every entry, and the entry k, v that has no slot,
+// into a table of the given number of buckets with new seeds.
+func (c *CuckooTable[K, V]) rebuild(buckets int, k K, v V) {
+	entries := make([]cuckooEntry[K, V], 0, c.size)
+	for i := range c.buckets {
+		b := &c.buckets[i]
+		for m := b.used; m != 0; m &= m - 1 {
+			slot := bits.TrailingZeros8(m)
+			entries = append(entries, cuckooEntry[K, V]{b.keys[slot], b.values[slot]})
+		}
+	}
+	entries = append(entries, c.stash...)
+	entries = append(entries, cuckooEntry[K, V]{k, v})
+
+	for attempt := 1; ; attempt++ {
+		c.init(buckets)
+		if c.insertAll(entries) {
+			return
+		}
+		if attempt%cuckooRebuilds == 0 {
+			if buckets*cuckooBucketSize > cuckooMaxSpread*len(entries) {
+				panic("hashtable: cuckoo table cannot place its keys; too many share a hash")
+			}
+			buckets *= 2
+		}
+	}
+}
+
+func (c *CuckooTable[K, V]) insertAll(entries []cuckooEntry[K, V]) bool {
+	for _, e := range entries {
+		if _, _, ok := c.insert(e.key, e.value); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *CuckooTable[K, V]) unstash(i int) {
+	last := len(c.stash) - 1
+	c.stash[i] = c.stash[last]
+	c.stash[last] = cuckooEntry[K, V]{}
+	c.stash = c.stash[:last]
+}
+
+func (c *CuckooTable[K, V]) drainStash() {
+	// Move stashed entries into buckets deletes have made room in
+	for i := 0; i < len(c.stash); {
+		e := c.stash[i]
+		hash := c.hasher.Hash(e.key)
+		placed := false
+		for way := range cuckooWays {
+			if c.place(c.bucketFor(hash, way), e.key, e.value) {
+				placed = true
+				break
+			}
+		}
+		if placed {
+			c.unstash(i)
+		} else {
+			i++
+		}
+	}
+}
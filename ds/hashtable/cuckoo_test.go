@@ -0,0 +1,159 @@
+package hashtable
+
+import (
+	"maps"
+	"math/bits"
+	"math/rand/v2"
+	"testing"
+)
+
+// checkCuckoo verifies that every key sits in one of its candidate
+// buckets or in the stash, and that the stash is within bounds.
+func checkCuckoo[K comparable, V any](t *testing.T, c *CuckooTable[K, V]) {
+	t.Helper()
+	live := 0
+	for i := range c.buckets {
+		for m := c.buckets[i].used; m != 0; m &= m - 1 {
+			slot := bits.TrailingZeros8(m)
+			hash := c.hasher.Hash(c.buckets[i].keys[slot])
+			home := false
+			for way := range cuckooWays {
+				home = home || c.bucketFor(hash, way) == uint64(i)
+			}
+			if !home {
+				t.Fatalf("bucket %d holds key %v, which does not hash there", i, c.buckets[i].keys[slot])
+			}
+			live++
+		}
+	}
+	if len(c.stash) > cuckooStashSize {
+		t.Fatalf("stash holds %d entries, limit %d", len(c.stash), cuckooStashSize)
+	}
+	if live+len(c.stash) != c.size {
+		t.Fatalf("%d entries in buckets and %d stashed, size %d", live, len(c.stash), c.size)
+	}
+}
+
+func TestCuckoo_Basic(t *testing.T) {
+	c := NewCuckoo[string, int](0)
+	if c.Len() != 0 {
+		t.Errorf("expected len 0, got %d", c.Len())
+	}
+
+	c.Put("foo", 1)
+	c.Put("bar", 2)
+	c.Put("foo", 3)
+	if v, ok := c.Get("foo"); !ok || v != 3 {
+		t.Errorf("expected 3, got %d (found=%v)", v, ok)
+	}
+	if _, ok := c.Get("baz"); ok {
+		t.Error("expected baz to be missing")
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected len 2, got %d", c.Len())
+	}
+
+	if !c.Delete("foo") || c.Delete("foo") {
+		t.Error("expected exactly one successful Delete(foo)")
+	}
+	if _, ok := c.Get("foo"); ok {
+		t.Error("expected foo to be gone")
+	}
+	if c.Len() != 1 {
+		t.Errorf("expected len 1, got %d", c.Len())
+	}
+}
+
+func TestCuckoo_Random(t *testing.T) {
+	rng := rand.New(rand.NewPCG(23, 23))
+	c := NewCuckoo[int, int](0)
+	expected := make(map[int]int)
+
+	for i := 0; i < 50000; i++ {
+		k := rng.IntN(3000)
+		if rng.IntN(3) == 0 {
+			_, ok := expected[k]
+			if c.Delete(k) != ok {
+				t.Fatalf("Delete(%d) disagreed with the model", k)
+			}
+			delete(expected, k)
+		} else {
+			c.Put(k, i)
+			expected[k] = i
+		}
+	}
+
+	checkCuckoo(t, c)
+	if got := maps.Collect(c.All()); !maps.Equal(got, expected) {
+		t.Fatalf("table has %d keys, expected %d", len(got), len(expected))
+	}
+	for k, v := range expected {
+		if got, ok := c.Get(k); !ok || got != v {
+			t.Fatalf("Get(%d) = %d, %v; expected %d", k, got, ok, v)
+		}
+	}
+}
+
+func TestCuckoo_HighLoad(t *testing.T) {
+	// Filling a presized table right up to its load limit forces long
+	// eviction chains but no growth
+	c := NewCuckoo[int, int](7000)
+	buckets := len(c.buckets)
+	limit := buckets * cuckooBucketSize * 7 / 8
+	for i := 0; i < limit; i++ {
+		c.Put(i, i)
+	}
+	checkCuckoo(t, c)
+	if len(c.buckets) != buckets {
+		t.Errorf("expected %d buckets, got %d", buckets, len(c.buckets))
+	}
+	for i := 0; i < limit; i++ {
+		if v, ok := c.Get(i); !ok || v != i {
+			t.Fatalf("Get(%d) = %d, %v", i, v, ok)
+		}
+	}
+}
+
+type modHasher uint64
+
+func (m modHasher) Hash(k int) uint64 { return uint64(k) % uint64(m) }
+
+func TestCuckoo_InsertCycles(t *testing.T) {
+	// Three hashes shared by nine keys each: every group overflows its
+	// two buckets, so inserts evict in cycles until they reach the stash,
+	// and the groups only fit once a rebuild finds seeds that keep their
+	// buckets apart
+	c := NewCuckoo[int, int](0, WithHasher[int](modHasher(3)))
+	for i := 0; i < 27; i++ {
+		c.Put(i, i)
+		checkCuckoo(t, c)
+	}
+	if len(c.stash) != 3 {
+		t.Errorf("expected one stashed key per hash, got %d", len(c.stash))
+	}
+	for i := 0; i < 27; i++ {
+		if v, ok := c.Get(i); !ok || v != i {
+			t.Fatalf("Get(%d) = %d, %v", i, v, ok)
+		}
+	}
+
+	// Deleting makes room in the buckets, which empties the stash
+	for i := 0; i < 3; i++ {
+		c.Delete(i)
+	}
+	checkCuckoo(t, c)
+	if len(c.stash) != 0 {
+		t.Errorf("expected stash to drain after deletes, %d left", len(c.stash))
+	}
+}
+
+func TestCuckoo_TooManyCollisions(t *testing.T) {
+	// One hash for every key leaves room for two buckets and the stash
+	c := NewCuckoo[int, int](0, WithHasher[int](constHasher{}))
+	fit := cuckooWays*cuckooBucketSize + cuckooStashSize
+	for i := 0; i < fit; i++ {
+		c.Put(i, i)
+	}
+	checkCuckoo(t, c)
+	mustPanic(t, "Put past capacity", func() { c.Put(fit, fit) })
+}
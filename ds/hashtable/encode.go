@@ -0,0 +1,304 @@
+package hashtable
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// Encoded tables are laid out as
+//
+//	magic "HTBL" | version (1 byte) | key count (uvarint)
+//	count × [key length (uvarint) | key | value length (uvarint) | value]
+//	CRC-32C of everything before it (4 bytes, little endian)
+//
+// Keys are hashed again when a table is read back, because every table
+// has its own hash seed, but the table is sized for the key count up
+// front so loading never resizes.
+
+const (
+	encodeMagic   = "HTBL"
+	encodeVersion = 1
+)
+
+var (
+	// ErrCorrupt is returned when encoded data is truncated, fails its
+	// checksum or does not decode.
+	ErrCorrupt = errors.New("hashtable: corrupt data")
+
+	errNoCodec = errors.New("hashtable: no codecs configured; use WithCodecs")
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// Codec converts keys or values of type T to and from bytes.
+type Codec[T any] interface {
+	// Append appends the encoding of v to dst and returns the result.
+	Append(dst []byte, v T) []byte
+	// Decode decodes a value from exactly the bytes Append produced.
+	Decode(data []byte) (T, error)
+}
+
+// WithCodecs sets the codecs WriteTo, ReadFrom, MarshalBinary and
+// UnmarshalBinary use for keys and values. New panics if their types
+// differ from the table's.
+func WithCodecs[K, V any](kc Codec[K], vc Codec[V]) Option {
+	return func(c *config) {
+		c.keyCodec = kc
+		c.valueCodec = vc
+	}
+}
+
+// IntegerCodec encodes integers as varints.
+type IntegerCodec[T Integer] struct{}
+
+func (IntegerCodec[T]) Append(dst []byte, v T) []byte {
+	return binary.AppendVarint(dst, int64(v))
+}
+
+func (IntegerCodec[T]) Decode(data []byte) (T, error) {
+	v, n := binary.Varint(data)
+	if n <= 0 || n != len(data) {
+		return 0, fmt.Errorf("hashtable: bad varint")
+	}
+	return T(v), nil
+}
+
+// StringCodec encodes strings as their bytes.
+type StringCodec[T ~string] struct{}
+
+func (StringCodec[T]) Append(dst []byte, v T) []byte {
+	return append(dst, v...)
+}
+
+func (StringCodec[T]) Decode(data []byte) (T, error) {
+	return T(data), nil
+}
+
+// WriteTo writes the table to w in a versioned, checksummed binary format
+// and returns the number of bytes written.
+func (h *HashTable[K, V]) WriteTo(w io.Writer) (int64, error) {
+	if h.keyCodec == nil {
+		return 0, errNoCodec
+	}
+	bw := bufio.NewWriter(w)
+	crc := crc32.New(crcTable)
+	out := io.MultiWriter(bw, crc)
+
+	buf := append([]byte(encodeMagic), encodeVersion)
+	buf = binary.AppendUvarint(buf, uint64(h.size))
+	written := int64(len(buf))
+	if _, err := out.Write(buf); err != nil {
+		return 0, err
+	}
+
+	var field []byte
+	for k, v := range h.All() {
+		buf = buf[:0]
+		field = h.keyCodec.Append(field[:0], k)
+		buf = binary.AppendUvarint(buf, uint64(len(field)))
+		buf = append(buf, field...)
+		field = h.valueCodec.Append(field[:0], v)
+		buf = binary.AppendUvarint(buf, uint64(len(field)))
+		buf = append(buf, field...)
+
+		if _, err := out.Write(buf); err != nil {
+			return written, err
+		}
+		written += int64(len(buf))
+	}
+
+	if _, err := bw.Write(binary.LittleEndian.AppendUint32(nil, crc.Sum32())); err != nil {
+		return written, err
+	}
+	written += 4
+	return written, bw.Flush()
+}
+
+// ReadFrom replaces the table's contents with a table read from r, as
+// written by WriteTo, and returns the number of bytes read. On error the
+// table is left unchanged. Unless r is an io.ByteReader it is buffered,
+// so ReadFrom may consume bytes past the end of the table.
+func (h *HashTable[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	keys, values, n, err := h.decode(r)
+	if err != nil {
+		return n, err
+	}
+	return n, h.load(keys, values)
+}
+
+func (h *HashTable[K, V]) decode(r io.Reader) (keys []K, values []V, n int64, err error) {
+	if h.keyCodec == nil {
+		return nil, nil, 0, errNoCodec
+	}
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	cr := &crcReader{r: br, crc: crc32.New(crcTable)}
+
+	header := make([]byte, len(encodeMagic)+1)
+	if _, err := io.ReadFull(cr, header); err != nil {
+		return nil, nil, cr.n, cr.fail(err)
+	}
+	if string(header[:len(encodeMagic)]) != encodeMagic {
+		return nil, nil, cr.n, fmt.Errorf("%w: bad magic", ErrCorrupt)
+	}
+	if v := header[len(encodeMagic)]; v != encodeVersion {
+		return nil, nil, cr.n, fmt.Errorf("hashtable: unsupported format version %d", v)
+	}
+	count, err := binary.ReadUvarint(cr)
+	if err != nil {
+		return nil, nil, cr.n, cr.fail(err)
+	}
+
+	// Decode everything before touching the table. count is not trusted
+	// until the checksum matches, so it does not size any allocation.
+	var field bytes.Buffer
+	readField := func() ([]byte, error) {
+		n, err := binary.ReadUvarint(cr)
+		if err != nil {
+			return nil, cr.fail(err)
+		}
+		if n > math.MaxInt32 {
+			return nil, fmt.Errorf("%w: field of %d bytes", ErrCorrupt, n)
+		}
+		// CopyN grows the buffer only as data actually arrives
+		field.Reset()
+		if _, err := io.CopyN(&field, cr, int64(n)); err != nil {
+			return nil, cr.fail(err)
+		}
+		return field.Bytes(), nil
+	}
+	for i := uint64(0); i < count; i++ {
+		data, err := readField()
+		if err != nil {
+			return nil, nil, cr.n, err
+		}
+		k, err := h.keyCodec.Decode(data)
+		if err != nil {
+			return nil, nil, cr.n, fmt.Errorf("%w: key %d: %v", ErrCorrupt, i, err)
+		}
+		if data, err = readField(); err != nil {
+			return nil, nil, cr.n, err
+		}
+		v, err := h.valueCodec.Decode(data)
+		if err != nil {
+			return nil, nil, cr.n, fmt.Errorf("%w: value %d: %v", ErrCorrupt, i, err)
+		}
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+
+	sum := cr.crc.Sum32()
+	trailer := make([]byte, 4)
+	if _, err := io.ReadFull(cr, trailer); err != nil {
+		return nil, nil, cr.n, cr.fail(err)
+	}
+	if binary.LittleEndian.Uint32(trailer) != sum {
+		return nil, nil, cr.n, fmt.Errorf("%w: checksum mismatch", ErrCorrupt)
+	}
+	return keys, values, cr.n, nil
+}
+
+func (h *HashTable[K, V]) load(keys []K, values []V) error {
+	old := *h
+	h.clear()
+	if c := h.capacityFor(len(keys)); c > h.cap {
+		h.rehash(c)
+	}
+	for i, k := range keys {
+		h.Put(k, values[i])
+	}
+	if h.size != len(keys) {
+		*h = old
+		h.mods++
+		return fmt.Errorf("%w: duplicate keys", ErrCorrupt)
+	}
+	return nil
+}
+
+func (h *HashTable[K, V]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := h.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (h *HashTable[K, V]) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	keys, values, _, err := h.decode(r)
+	if err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrCorrupt, r.Len())
+	}
+	return h.load(keys, values)
+}
+
+func (h *HashTable[K, V]) clear() {
+	// Empty the table, dropping back to its shrink floor
+	h.data = make([]Entry[K, V], h.minCap)
+	h.cap = h.minCap
+	h.size = 0
+	h.tombstoneSize = 0
+	h.mods++
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// crcReader checksums and counts the bytes read through it, and keeps
+// the first error from the underlying reader other than io.EOF.
+type crcReader struct {
+	r   byteReader
+	crc hash.Hash32
+	n   int64
+	err error
+}
+
+func (c *crcReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.crc.Write(p[:n])
+	c.n += int64(n)
+	c.keep(err)
+	return n, err
+}
+
+func (c *crcReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.crc.Write([]byte{b})
+		c.n++
+	}
+	c.keep(err)
+	return b, err
+}
+
+func (c *crcReader) keep(err error) {
+	if err != nil && err != io.EOF && c.err == nil {
+		c.err = err
+	}
+}
+
+func (c *crcReader) fail(err error) error {
+	// Errors from the underlying reader pass through; anything else,
+	// running out of data or an overflowing varint, means bad input
+	if c.err != nil {
+		return c.err
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: truncated", ErrCorrupt)
+	}
+	return fmt.Errorf("%w: %v", ErrCorrupt, err)
+}
@@ -0,0 +1,248 @@
+package hashtable
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"maps"
+	"strings"
+	"testing"
+)
+
+func newEncodable() *HashTable[int, string] {
+	return New[int, string](0, WithCodecs[int, string](IntegerCodec[int]{}, StringCodec[string]{}))
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	src := newEncodable()
+	for i := -500; i < 500; i++ {
+		src.Put(i, strings.Repeat("x", i&15))
+	}
+	for i := 0; i < 500; i += 7 {
+		src.Delete(i)
+	}
+	want := maps.Collect(src.All())
+
+	data, err := src.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := newEncodable()
+	dst.Put(12345, "replaced")
+	if err := dst.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got := maps.Collect(dst.All()); !maps.Equal(got, want) {
+		t.Errorf("UnmarshalBinary: expected %d pairs, got %d", len(want), len(got))
+	}
+	if dst.Len() != src.Len() {
+		t.Errorf("expected len %d, got %d", src.Len(), dst.Len())
+	}
+
+	var buf bytes.Buffer
+	n, err := src.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("WriteTo: n=%d err=%v, buffer has %d bytes", n, err, buf.Len())
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("WriteTo and MarshalBinary disagree")
+	}
+	rt := New[int, string](0, WithRobinHood(), WithCodecs[int, string](IntegerCodec[int]{}, StringCodec[string]{}))
+	// Hide the ByteReader so ReadFrom has to buffer, which may read past
+	// the end of the table but does not count the extra bytes
+	buf.WriteString("tail")
+	m, err := rt.ReadFrom(struct{ io.Reader }{&buf})
+	if err != nil || m != n {
+		t.Fatalf("ReadFrom: n=%d err=%v, expected %d bytes", m, err, n)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected the buffered reader to consume the tail, %d bytes left", buf.Len())
+	}
+	if got := maps.Collect(rt.All()); !maps.Equal(got, want) {
+		t.Errorf("ReadFrom: expected %d pairs, got %d", len(want), len(got))
+	}
+	checkRobinHood(t, rt)
+}
+
+func TestEncode_Empty(t *testing.T) {
+	data, err := newEncodable().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newEncodable()
+	h.Put(1, "one")
+	if err := h.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if h.Len() != 0 {
+		t.Errorf("expected empty table, got %d keys", h.Len())
+	}
+}
+
+func TestEncode_Corruption(t *testing.T) {
+	src := newEncodable()
+	for i := 0; i < 50; i++ {
+		src.Put(i*1000, "value")
+	}
+	data, _ := src.MarshalBinary()
+
+	check := func(name string, bad []byte) {
+		t.Helper()
+		h := newEncodable()
+		h.Put(-1, "before")
+		if err := h.UnmarshalBinary(bad); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+		// Failed loads leave the table alone
+		if v, ok := h.Get(-1); !ok || v != "before" || h.Len() != 1 {
+			t.Errorf("%s: table changed on error", name)
+		}
+	}
+
+	for i := range data {
+		bad := bytes.Clone(data)
+		bad[i] ^= 0x20
+		check("flip", bad)
+	}
+	for i := range data {
+		check("truncate", data[:i])
+	}
+	check("trailing", append(bytes.Clone(data), 0))
+
+	h := newEncodable()
+	if err := h.UnmarshalBinary(data[:len(data)/2]); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("truncated: expected ErrCorrupt, got %v", err)
+	}
+	bad := bytes.Clone(data)
+	bad[len(bad)-1] ^= 1
+	if err := h.UnmarshalBinary(bad); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("bad checksum: expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestEncode_Version(t *testing.T) {
+	data, _ := newEncodable().MarshalBinary()
+	data[len(encodeMagic)] = encodeVersion + 1
+	err := newEncodable().UnmarshalBinary(data)
+	if err == nil || errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected unsupported version error, got %v", err)
+	}
+}
+
+func TestEncode_DuplicateKeys(t *testing.T) {
+	// Two records for key 1, with a valid checksum
+	var c IntegerCodec[int]
+	h := New[int, int](0, WithCodecs[int, int](c, c))
+	h.Put(1, 10)
+	h.Put(2, 20)
+	data, _ := h.MarshalBinary()
+	body := bytes.Replace(data[:len(data)-4], c.Append(nil, 2), c.Append(nil, 1), 1)
+
+	var buf bytes.Buffer
+	buf.Write(body)
+	buf.Write(crcOf(body))
+
+	dst := New[int, int](0, WithCodecs[int, int](c, c))
+	dst.Put(3, 30)
+	if err := dst.UnmarshalBinary(buf.Bytes()); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+	if v, ok := dst.Get(3); !ok || v != 30 || dst.Len() != 1 {
+		t.Errorf("table changed on error")
+	}
+}
+
+func TestEncode_Codecs(t *testing.T) {
+	h := New[int, int](0)
+	if _, err := h.MarshalBinary(); err == nil {
+		t.Errorf("MarshalBinary: expected error without codecs")
+	}
+	if err := h.UnmarshalBinary(nil); err == nil {
+		t.Errorf("UnmarshalBinary: expected error without codecs")
+	}
+	for _, tt := range []struct {
+		f    func()
+		want string
+	}{
+		{
+			func() { New[string, int](0, WithCodecs[int, int](IntegerCodec[int]{}, IntegerCodec[int]{})) },
+			"hashtable: hashtable.IntegerCodec[int] cannot encode keys of type string",
+		},
+		{
+			func() { New[int, string](0, WithCodecs[int, int](IntegerCodec[int]{}, IntegerCodec[int]{})) },
+			"hashtable: hashtable.IntegerCodec[int] cannot encode values of type string",
+		},
+	} {
+		if got := panicMessage(tt.f); got != tt.want {
+			t.Errorf("expected panic %q, got %q", tt.want, got)
+		}
+	}
+
+	var ic IntegerCodec[int8]
+	if _, err := ic.Decode([]byte{0x80}); err == nil {
+		t.Errorf("IntegerCodec: expected error for truncated varint")
+	}
+	if _, err := ic.Decode(append(ic.Append(nil, -3), 0)); err == nil {
+		t.Errorf("IntegerCodec: expected error for trailing bytes")
+	}
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add([]byte("hello"))
+	f.Add([]byte{0, 0, 0, 1, 2, 3, 255, 254})
+	f.Fuzz(func(t *testing.T, in []byte) {
+		src := New[int16, string](0, WithCodecs[int16, string](IntegerCodec[int16]{}, StringCodec[string]{}))
+		for i := 0; i+1 < len(in); i += 2 {
+			k := int16(in[i])<<8 | int16(in[i+1])
+			src.Put(k, string(in[i:]))
+			if in[i]&1 == 1 {
+				src.Delete(k - 1)
+			}
+		}
+		data, err := src.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst := New[int16, string](0, WithCodecs[int16, string](IntegerCodec[int16]{}, StringCodec[string]{}))
+		if err := dst.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if !maps.Equal(maps.Collect(dst.All()), maps.Collect(src.All())) {
+			t.Errorf("round trip changed the table")
+		}
+	})
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	src := New[int, string](0, WithCodecs[int, string](IntegerCodec[int]{}, StringCodec[string]{}))
+	f.Add([]byte{})
+	data, _ := src.MarshalBinary()
+	f.Add(data)
+	src.Put(1, "a")
+	src.Put(-70000, "bcd")
+	data, _ = src.MarshalBinary()
+	f.Add(data)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		h := newEncodable()
+		if err := h.UnmarshalBinary(data); err != nil {
+			return
+		}
+		out, err := h.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		again := newEncodable()
+		if err := again.UnmarshalBinary(out); err != nil {
+			t.Fatal(err)
+		}
+		if !maps.Equal(maps.Collect(again.All()), maps.Collect(h.All())) {
+			t.Errorf("re-encoding changed the table")
+		}
+	})
+}
+
+func crcOf(data []byte) []byte {
+	return binary.LittleEndian.AppendUint32(nil, crc32.Checksum(data, crcTable))
+}
@@ -0,0 +1,91 @@
+package hashtable
+
+import (
+	"hash/maphash"
+	"math/rand/v2"
+	"unsafe"
+)
+
+// Hasher hashes keys of type K. Equal keys must have equal hashes, and a
+// Hasher must not change its output while a table is using it.
+type Hasher[K any] interface {
+	Hash(key K) uint64
+}
+
+// Integer is the set of integer key types IntegerHasher supports.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// ByteArray is the set of fixed-size byte array key types (hashes, UUIDs,
+// addresses) ByteArrayHasher supports.
+type ByteArray interface {
+	~[4]byte | ~[8]byte | ~[12]byte | ~[16]byte | ~[20]byte | ~[32]byte | ~[64]byte
+}
+
+// NewHasher returns the default hasher, which works for any comparable
+// key using hash/maphash with a random seed. Every table that is not
+// given a hasher gets its own, so an attacker who learns one table's
+// collisions cannot reuse them against another.
+func NewHasher[K comparable]() Hasher[K] {
+	return comparableHasher[K]{seed: maphash.MakeSeed()}
+}
+
+type comparableHasher[K comparable] struct {
+	seed maphash.Seed
+}
+
+func (h comparableHasher[K]) Hash(key K) uint64 {
+	return maphash.Comparable(h.seed, key)
+}
+
+// IntegerHasher hashes integer keys by mixing them with a random seed.
+type IntegerHasher[K Integer] struct {
+	seed uint64
+}
+
+func NewIntegerHasher[K Integer]() IntegerHasher[K] {
+	return IntegerHasher[K]{seed: rand.Uint64()}
+}
+
+func (h IntegerHasher[K]) Hash(key K) uint64 {
+	return mix64(uint64(key) ^ h.seed)
+}
+
+func mix64(x uint64) uint64 {
+	// splitmix64 finalizer: every input bit affects every output bit
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// StringHasher hashes string keys with hash/maphash and a random seed.
+type StringHasher[K ~string] struct {
+	seed maphash.Seed
+}
+
+func NewStringHasher[K ~string]() StringHasher[K] {
+	return StringHasher[K]{seed: maphash.MakeSeed()}
+}
+
+func (h StringHasher[K]) Hash(key K) uint64 {
+	return maphash.String(h.seed, string(key))
+}
+
+// ByteArrayHasher hashes fixed-size byte array keys with hash/maphash and
+// a random seed.
+type ByteArrayHasher[K ByteArray] struct {
+	seed maphash.Seed
+}
+
+func NewByteArrayHasher[K ByteArray]() ByteArrayHasher[K] {
+	return ByteArrayHasher[K]{seed: maphash.MakeSeed()}
+}
+
+func (h ByteArrayHasher[K]) Hash(key K) uint64 {
+	return maphash.Bytes(h.seed, unsafe.Slice(&key[0], len(key)))
+}
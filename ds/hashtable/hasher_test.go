@@ -0,0 +1,164 @@
+package hashtable
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHasher_BuiltIns(t *testing.T) {
+	ints := New[int64, int](4, WithHasher(NewIntegerHasher[int64]()))
+	strs := New[string, int](4, WithHasher(NewStringHasher[string]()))
+	arrays := New[[16]byte, int](4, WithHasher(NewByteArrayHasher[[16]byte]()))
+
+	for i := 0; i < 1000; i++ {
+		ints.Put(int64(i)<<32, i)
+		strs.Put(fmt.Sprint(i), i)
+		arrays.Put([16]byte{byte(i), byte(i >> 8)}, i)
+	}
+	for i := 0; i < 1000; i++ {
+		if v, ok := ints.Get(int64(i) << 32); !ok || v != i {
+			t.Fatalf("int key %d: found=%v, val=%v", i, ok, v)
+		}
+		if v, ok := strs.Get(fmt.Sprint(i)); !ok || v != i {
+			t.Fatalf("string key %d: found=%v, val=%v", i, ok, v)
+		}
+		if v, ok := arrays.Get([16]byte{byte(i), byte(i >> 8)}); !ok || v != i {
+			t.Fatalf("array key %d: found=%v, val=%v", i, ok, v)
+		}
+	}
+	if ints.Len() != 1000 || strs.Len() != 1000 || arrays.Len() != 1000 {
+		t.Errorf("expected 1000 keys each, got %d, %d, %d", ints.Len(), strs.Len(), arrays.Len())
+	}
+}
+
+type named string
+
+func TestHasher_DerivedTypes(t *testing.T) {
+	type id uint16
+	ids := New[id, string](0, WithHasher(NewIntegerHasher[id]()))
+	ids.Put(7, "seven")
+	if v, _ := ids.Get(7); v != "seven" {
+		t.Errorf("expected \"seven\", got %q", v)
+	}
+
+	names := New[named, int](0, WithHasher(NewStringHasher[named]()))
+	names.Put("x", 1)
+	if v, _ := names.Get("x"); v != 1 {
+		t.Errorf("expected 1, got %d", v)
+	}
+}
+
+func TestHasher_MismatchPanics(t *testing.T) {
+	mustPanic(t, "string hasher for int keys", func() {
+		New[int, int](0, WithHasher(NewStringHasher[string]()))
+	})
+	mustPanic(t, "int64 hasher for int keys", func() {
+		New[int, int](0, WithHasher(NewIntegerHasher[int64]()))
+	})
+
+	want := "hashtable: hashtable.StringHasher[string] cannot hash keys of type int"
+	if got := panicMessage(func() { New[int, int](0, WithHasher(NewStringHasher[string]())) }); got != want {
+		t.Errorf("expected panic %q, got %q", want, got)
+	}
+}
+
+func TestHasher_NewWithHasher(t *testing.T) {
+	ht := NewWithHasher[int, string](0, NewIntegerHasher[int](), WithRobinHood())
+	if _, ok := ht.hasher.(IntegerHasher[int]); !ok || !ht.robinHood {
+		t.Errorf("expected an IntegerHasher and Robin Hood mode, got %T, %v", ht.hasher, ht.robinHood)
+	}
+	ht.Put(1, "one")
+	if v, _ := ht.Get(1); v != "one" {
+		t.Errorf("expected \"one\", got %q", v)
+	}
+
+	// The hasher given directly wins over one passed as an option
+	ht = NewWithHasher[int, string](0, constHasher{}, WithHasher[int](NewIntegerHasher[int]()))
+	if _, ok := ht.hasher.(constHasher); !ok {
+		t.Errorf("expected constHasher, got %T", ht.hasher)
+	}
+}
+
+func TestHasher_KeysThatPrintAlike(t *testing.T) {
+	// Both print as {x y z}, which used to make them collide on every probe
+	type pair struct{ A, B string }
+	a, b := pair{"x y", "z"}, pair{"x", "y z"}
+	ht := New[pair, int](0)
+	ht.Put(a, 1)
+	ht.Put(b, 2)
+	if ht.hasher.Hash(a) == ht.hasher.Hash(b) {
+		t.Error("expected distinct hashes for keys that print alike")
+	}
+	if v, _ := ht.Get(a); v != 1 {
+		t.Errorf("expected 1, got %d", v)
+	}
+
+	// Pointer fields hash by identity, not by what they point to
+	type node struct{ next *int }
+	x, y := 1, 1
+	nodes := New[node, string](0)
+	nodes.Put(node{&x}, "x")
+	nodes.Put(node{&y}, "y")
+	if v, _ := nodes.Get(node{&x}); v != "x" {
+		t.Errorf("expected \"x\", got %q", v)
+	}
+	if nodes.Len() != 2 {
+		t.Errorf("expected 2 keys, got %d", nodes.Len())
+	}
+}
+
+func TestHasher_PerTableSeeds(t *testing.T) {
+	a, b := New[int, int](0), New[int, int](0)
+	same := 0
+	for i := 0; i < 64; i++ {
+		if a.hasher.Hash(i) == b.hasher.Hash(i) {
+			same++
+		}
+	}
+	if same == 64 {
+		t.Error("expected tables to use different seeds")
+	}
+}
+
+func TestHasher_AllocationFree(t *testing.T) {
+	ints := New[int, int](1024)
+	strs := New[string, int](1024, WithHasher(NewStringHasher[string]()))
+	arrays := New[[32]byte, int](1024, WithHasher(NewByteArrayHasher[[32]byte]()))
+	key := "some-key"
+	ints.Put(1, 1)
+	strs.Put(key, 1)
+	arrays.Put([32]byte{1}, 1)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		ints.Put(1, 2)
+		ints.Get(1)
+		strs.Put(key, 2)
+		strs.Get(key)
+		arrays.Put([32]byte{1}, 2)
+		arrays.Get([32]byte{1})
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v per run", allocs)
+	}
+}
+
+type maxHasher struct{}
+
+func (maxHasher) Hash(int) uint64 { return ^uint64(0) }
+
+func TestHasher_HashNearWrap(t *testing.T) {
+	// Probing from a hash within cap of 2^64 must reduce it first, or the
+	// slot sequence wraps differently in Delete than in Put and Get
+	ht := New[int, int](10, WithHasher[int](maxHasher{}))
+	ht.Put(1, 1)
+	ht.Put(2, 2)
+	if !ht.Delete(2) {
+		t.Fatal("expected Delete(2) to find the key")
+	}
+	if _, ok := ht.Get(2); ok {
+		t.Error("expected 2 to be gone")
+	}
+	if v, ok := ht.Get(1); !ok || v != 1 {
+		t.Errorf("expected 1, got %d (found=%v)", v, ok)
+	}
+}
@@ -2,7 +2,15 @@ package hashtable
 
 import (
 	"fmt"
-	"hash/fnv"
+	"math"
+	"slices"
+)
+
+const (
+	defaultMaxLoad = 0.7
+	defaultGrowth  = 2
+	// Compact never shrinks below minCapacity slots
+	minCapacity = 8
 )
 
 type Entry[K comparable, V any] struct {
@@ -10,6 +18,8 @@ type Entry[K comparable, V any] struct {
 	value     V
 	occupied  bool
 	tombstone bool
+	// dist is how far the entry sits from its home slot (Robin Hood mode)
+	dist int
 }
 
 type HashTable[K comparable, V any] struct {
@@ -17,16 +27,146 @@ type HashTable[K comparable, V any] struct {
 	size          int
 	tombstoneSize int
 	cap           int
+	// minCap is the floor for automatic shrinking, set by New and Reserve
+	minCap int
+	// mods counts structural changes (keys added or removed, resizes) so
+	// iterators can detect them
+	mods int
+	// resizes counts rehashes that changed the capacity
+	resizes int
+	hasher  Hasher[K]
+	// robinHood selects Robin Hood probing with backward-shift deletion
+	robinHood bool
+	// maxLoad is the highest fraction of slots, live or tombstone, that
+	// may be in use; growth multiplies the capacity when it is exceeded
+	maxLoad float64
+	growth  float64
+	// powerOfTwo keeps every capacity a power of two
+	powerOfTwo bool
+	// keyCodec and valueCodec serialize the table; nil if not configured
+	keyCodec   Codec[K]
+	valueCodec Codec[V]
+}
+
+type config struct {
+	hasher     any
+	robinHood  bool
+	maxLoad    float64
+	growth     float64
+	powerOfTwo bool
+	keyCodec   any
+	valueCodec any
+}
+
+// Option configures a HashTable.
+type Option func(*config)
+
+// WithHasher makes the table hash keys with h instead of the default
+// maphash-based hasher. New panics if h's key type differs from the table's.
+func WithHasher[K any](h Hasher[K]) Option {
+	return func(c *config) {
+		c.hasher = h
+	}
+}
+
+// WithRobinHood makes the table use Robin Hood probing: an insert takes
+// the slot of any entry that is closer to its home slot than the new one
+// would be, and the displaced entry moves on instead. This keeps probe
+// lengths short and even at high load. Deletes shift the following
+// entries back rather than leaving tombstones.
+func WithRobinHood() Option {
+	return func(c *config) {
+		c.robinHood = true
+	}
+}
+
+// WithMaxLoadFactor sets the fraction of slots, in (0, 1), that may be
+// filled by keys and tombstones before the table grows. The default is 0.7.
+// Lower values trade memory for shorter probes.
+func WithMaxLoadFactor(f float64) Option {
+	return func(c *config) {
+		c.maxLoad = f
+	}
+}
+
+// WithGrowthFactor sets how much the capacity is multiplied by, greater
+// than 1, each time the table grows. The default is 2.
+func WithGrowthFactor(g float64) Option {
+	return func(c *config) {
+		c.growth = g
+	}
+}
+
+// WithPowerOfTwo rounds the initial capacity, and every capacity after
+// it, up to a power of two.
+func WithPowerOfTwo() Option {
+	return func(c *config) {
+		c.powerOfTwo = true
+	}
 }
 
-func New[K comparable, V any](initialCap int) *HashTable[K, V] {
+func New[K comparable, V any](initialCap int, opts ...Option) *HashTable[K, V] {
+	cfg := config{
+		maxLoad: defaultMaxLoad,
+		growth:  defaultGrowth,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if !(cfg.maxLoad > 0 && cfg.maxLoad < 1) {
+		panic(fmt.Sprintf("hashtable: max load factor %v out of range (0, 1)", cfg.maxLoad))
+	}
+	if !(cfg.growth > 1) {
+		panic(fmt.Sprintf("hashtable: growth factor %v must be greater than 1", cfg.growth))
+	}
+
+	hasher := resolveHasher[K](cfg)
+
 	if initialCap <= 0 {
 		initialCap = 16
 	}
-	return &HashTable[K, V]{
-		data: make([]Entry[K, V], initialCap),
-		cap:  initialCap,
+	if cfg.powerOfTwo {
+		initialCap = roundUpPow2(initialCap)
+	}
+	h := &HashTable[K, V]{
+		data:       make([]Entry[K, V], initialCap),
+		cap:        initialCap,
+		minCap:     initialCap,
+		hasher:     hasher,
+		robinHood:  cfg.robinHood,
+		maxLoad:    cfg.maxLoad,
+		growth:     cfg.growth,
+		powerOfTwo: cfg.powerOfTwo,
+	}
+	if cfg.keyCodec != nil {
+		var ok bool
+		h.keyCodec, ok = cfg.keyCodec.(Codec[K])
+		if !ok {
+			panic(fmt.Sprintf("hashtable: %T cannot encode keys of type %T", cfg.keyCodec, *new(K)))
+		}
+		h.valueCodec, ok = cfg.valueCodec.(Codec[V])
+		if !ok {
+			panic(fmt.Sprintf("hashtable: %T cannot encode values of type %T", cfg.valueCodec, *new(V)))
+		}
 	}
+	return h
+}
+
+// NewWithHasher is New with the table hashing keys with h. Unlike the
+// WithHasher option, it checks h's key type at compile time.
+func NewWithHasher[K comparable, V any](initialCap int, h Hasher[K], opts ...Option) *HashTable[K, V] {
+	return New[K, V](initialCap, append(slices.Clip(opts), WithHasher(h))...)
+}
+
+func resolveHasher[K comparable](cfg config) Hasher[K] {
+	if cfg.hasher == nil {
+		return NewHasher[K]()
+	}
+	hasher, ok := cfg.hasher.(Hasher[K])
+	if !ok {
+		panic(fmt.Sprintf("hashtable: %T cannot hash keys of type %T", cfg.hasher, *new(K)))
+	}
+	return hasher
 }
 
 func (h *HashTable[K, V]) Len() int {
@@ -34,11 +174,11 @@ func (h *HashTable[K, V]) Len() int {
 }
 
 func (h *HashTable[K, V]) Put(k K, v V) {
-	key := hashKey(k) % uint64(h.cap)
-
-	if float64((h.size+h.tombstoneSize)/h.cap) > 0.7 {
-		h.resize()
+	if h.robinHood {
+		h.putRobinHood(k, v)
+		return
 	}
+	key := h.hasher.Hash(k) % uint64(h.cap)
 
 	firstTombstone := -1
 	for i := 0; i < h.cap; i++ {
@@ -59,6 +199,12 @@ func (h *HashTable[K, V]) Put(k K, v V) {
 		}
 
 		if firstTombstone < 0 {
+			if h.overloaded() {
+				// Taking a free slot would exceed the load factor
+				h.resize()
+				h.Put(k, v)
+				return
+			}
 			// Insert at first unoccupied position
 			h.data[idx] = Entry[K, V]{
 				key:       k,
@@ -77,6 +223,7 @@ func (h *HashTable[K, V]) Put(k K, v V) {
 			h.tombstoneSize -= 1
 		}
 		h.size += 1
+		h.mods++
 		return
 	}
 
@@ -90,13 +237,17 @@ func (h *HashTable[K, V]) Put(k K, v V) {
 		}
 		h.size += 1
 		h.tombstoneSize -= 1
+		h.mods++
 		return
 	}
 	panic("Hashtable is full!")
 }
 
 func (h *HashTable[K, V]) Get(k K) (V, bool) {
-	key := hashKey(k) % uint64(h.cap)
+	if h.robinHood {
+		return h.getRobinHood(k)
+	}
+	key := h.hasher.Hash(k) % uint64(h.cap)
 	var zero V
 
 	for i := 0; i < h.cap; i++ {
@@ -114,21 +265,17 @@ func (h *HashTable[K, V]) Get(k K) (V, bool) {
 }
 
 func (h *HashTable[K, V]) Delete(k K) bool {
-	key := hashKey(k)
-	var zeroKey K
-	var zeroVal V
+	if h.robinHood {
+		return h.deleteRobinHood(k)
+	}
+	key := h.hasher.Hash(k) % uint64(h.cap)
 
 	for i := 0; i < h.cap; i++ {
 		idx := (key + uint64(i)) % uint64(h.cap)
 
 		if h.data[idx].occupied {
 			if h.data[idx].key == k {
-				h.data[idx].occupied = false
-				h.data[idx].tombstone = true
-				h.data[idx].key = zeroKey
-				h.data[idx].value = zeroVal
-				h.size -= 1
-				h.tombstoneSize += 1
+				h.removeAt(idx)
 				return true
 			}
 		} else if !h.data[idx].occupied && !h.data[idx].tombstone {
@@ -139,41 +286,110 @@ func (h *HashTable[K, V]) Delete(k K) bool {
 	return false
 }
 
-func (h *HashTable[K, V]) resize() {
-	newData := make([]Entry[K, V], h.cap*2)
+func (h *HashTable[K, V]) removeAt(idx uint64) {
+	// Remove the entry in slot idx, leaving a tombstone unless in Robin
+	// Hood mode
+	if h.robinHood {
+		h.shiftBack(idx)
+	} else {
+		h.data[idx] = Entry[K, V]{tombstone: true}
+		h.tombstoneSize += 1
+	}
+	h.size -= 1
+	h.mods++
+	h.afterDelete()
+}
 
-	for i := 0; i < h.cap; i++ {
-		d := h.data[i]
-		if d.occupied {
-			newKey := hashKey(d.key)
-			newIdx := newKey % uint64(h.cap*2)
-			ent := newData[newIdx]
-			for ent.occupied {
-				newIdx := (newKey + 1) % uint64(h.cap*2)
-				ent = newData[newIdx]
-			}
+// Compact rehashes the table into the smallest capacity that holds its
+// keys below the maximum load factor, dropping all tombstones. It also
+// clears the shrink floor set by New or Reserve, so memory can be
+// reclaimed from a table that was sized for more keys than it now holds.
+func (h *HashTable[K, V]) Compact() {
+	h.minCap = minCapacity
+	h.rehash(h.capacityFor(h.size))
+}
 
-			newData[newIdx].occupied = true
-			newData[newIdx].key = d.key
-			newData[newIdx].value = d.value
-		}
+// Reserve grows the table so it can hold n keys without resizing, and
+// stops it from shrinking automatically below that capacity.
+func (h *HashTable[K, V]) Reserve(n int) {
+	c := h.capacityFor(n)
+	h.minCap = max(h.minCap, c)
+	if c > h.cap {
+		h.rehash(c)
 	}
-	h.data = newData
-	h.cap *= 2
-	h.tombstoneSize = 0
 }
 
-func hashKey[K comparable](key K) uint64 {
-	h := fnv.New64a()
+func (h *HashTable[K, V]) capacityFor(n int) int {
+	// Fewest slots holding n keys within the load factor, keeping one free
+	c := max(int(math.Ceil(float64(n)/h.maxLoad)), n+1, minCapacity)
+	if h.powerOfTwo {
+		c = roundUpPow2(c)
+	}
+	return c
+}
+
+func (h *HashTable[K, V]) overloaded() bool {
+	// Whether filling one more free slot would exceed the load factor
+	return float64(h.size+h.tombstoneSize+1) > h.maxLoad*float64(h.cap)
+}
 
-	switch k := any(key).(type) {
-	case string:
-		h.Write([]byte(k))
-	case int:
-		fmt.Fprintf(h, "%d", k)
-	default:
-		fmt.Fprintf(h, "%v", k)
+func (h *HashTable[K, V]) afterDelete() {
+	// Shrink a mostly empty table, to half the maximum load so it does not
+	// immediately grow again; otherwise rehash in place if tombstones
+	// outnumber live keys and are clogging probe chains
+	if h.cap > h.minCap && float64(h.size) < h.maxLoad/4*float64(h.cap) {
+		h.rehash(max(h.cap/2, h.minCap))
+	} else if h.tombstoneSize > h.size && h.tombstoneSize > h.cap/4 {
+		h.rehash(h.cap)
 	}
+}
 
-	return h.Sum64()
+func (h *HashTable[K, V]) resize() {
+	// Rehashing clears tombstones, so only grow if live keys need the room
+	if h.tombstoneSize > h.size {
+		h.rehash(h.cap)
+		return
+	}
+	newCap := max(int(math.Ceil(float64(h.cap)*h.growth)), h.cap+1)
+	if h.powerOfTwo {
+		newCap = roundUpPow2(newCap)
+	}
+	h.rehash(newCap)
+}
+
+func roundUpPow2(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+func (h *HashTable[K, V]) rehash(newCap int) {
+	newData := make([]Entry[K, V], newCap)
+
+	for i := 0; i < h.cap; i++ {
+		d := h.data[i]
+		if !d.occupied {
+			continue
+		}
+		if h.robinHood {
+			d.dist = 0
+			insertRobinHood(newData, h.hasher.Hash(d.key)%uint64(newCap), d)
+			continue
+		}
+		// No tombstones or duplicates in the new table: take the first free slot
+		idx := h.hasher.Hash(d.key) % uint64(newCap)
+		for newData[idx].occupied {
+			idx = (idx + 1) % uint64(newCap)
+		}
+		newData[idx] = d
+	}
+	if newCap != h.cap {
+		h.resizes++
+	}
+	h.data = newData
+	h.cap = newCap
+	h.tombstoneSize = 0
+	h.mods++
 }
@@ -158,3 +158,216 @@ func TestHashTable_TombstoneReuse(t *testing.T) {
 		t.Errorf("expected 4, got %d", val)
 	}
 }
+
+func TestHashTable_ShrinksAfterDeletes(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithRobinHood()}} {
+		ht := New[int, int](16, opts...)
+		for i := 0; i < 1000; i++ {
+			ht.Put(i, i)
+		}
+		grown := ht.cap
+		for i := 10; i < 1000; i++ {
+			ht.Delete(i)
+		}
+
+		if ht.cap >= grown/8 || ht.cap < 16 {
+			t.Errorf("expected capacity to shrink from %d towards 16, got %d", grown, ht.cap)
+		}
+		for i := 0; i < 10; i++ {
+			if v, ok := ht.Get(i); !ok || v != i {
+				t.Errorf("key %d: found=%v, val=%v", i, ok, v)
+			}
+		}
+	}
+}
+
+func TestHashTable_NoShrinkBelowInitialCap(t *testing.T) {
+	ht := New[int, int](1024)
+	for i := 0; i < 100; i++ {
+		ht.Put(i, i)
+	}
+	for i := 0; i < 100; i++ {
+		ht.Delete(i)
+	}
+	if ht.cap != 1024 {
+		t.Errorf("expected capacity to stay 1024, got %d", ht.cap)
+	}
+}
+
+func TestHashTable_RehashesAwayTombstones(t *testing.T) {
+	ht := New[int, int](64)
+	for i := 0; i < 40; i++ {
+		ht.Put(i, i)
+	}
+	for i := 0; i < 20; i++ {
+		ht.Delete(i)
+	}
+	if ht.tombstoneSize != 20 {
+		t.Fatalf("expected 20 tombstones, got %d", ht.tombstoneSize)
+	}
+
+	// Once tombstones outnumber live keys the table is rebuilt at the same size
+	ht.Delete(20)
+	if ht.tombstoneSize != 0 || ht.cap != 64 {
+		t.Errorf("expected in-place rehash, got %d tombstones and capacity %d", ht.tombstoneSize, ht.cap)
+	}
+	for i := 0; i < 40; i++ {
+		if _, ok := ht.Get(i); ok != (i > 20) {
+			t.Errorf("key %d: found=%v", i, ok)
+		}
+	}
+}
+
+func TestHashTable_Compact(t *testing.T) {
+	ht := New[int, int](4096)
+	for i := 0; i < 200; i++ {
+		ht.Put(i, i)
+	}
+	for i := 0; i < 200; i += 2 {
+		ht.Delete(i)
+	}
+
+	ht.Compact()
+	if ht.cap != 143 || ht.tombstoneSize != 0 {
+		t.Errorf("expected capacity 143 without tombstones, got %d with %d", ht.cap, ht.tombstoneSize)
+	}
+	for i := 0; i < 200; i++ {
+		if _, ok := ht.Get(i); ok != (i%2 == 1) {
+			t.Errorf("key %d: found=%v", i, ok)
+		}
+	}
+
+	empty := New[string, int](0)
+	empty.Compact()
+	if empty.cap != minCapacity {
+		t.Errorf("expected empty table to compact to %d slots, got %d", minCapacity, empty.cap)
+	}
+	empty.Put("a", 1)
+	if v, _ := empty.Get("a"); v != 1 {
+		t.Errorf("expected 1, got %d", v)
+	}
+}
+
+func TestHashTable_Reserve(t *testing.T) {
+	ht := New[int, int](0)
+	ht.Reserve(1000)
+	reserved := ht.cap
+	if reserved < 1000 {
+		t.Fatalf("expected room for 1000 keys, got capacity %d", reserved)
+	}
+
+	for i := 0; i < 1000; i++ {
+		ht.Put(i, i)
+	}
+	if ht.cap != reserved {
+		t.Errorf("expected no resize after Reserve, capacity went %d -> %d", reserved, ht.cap)
+	}
+	for i := 0; i < 1000; i++ {
+		ht.Delete(i)
+	}
+	if ht.cap != reserved {
+		t.Errorf("expected no shrink below reserved capacity %d, got %d", reserved, ht.cap)
+	}
+
+	// Reserving less than the current capacity changes nothing
+	ht.Reserve(10)
+	if ht.cap != reserved {
+		t.Errorf("expected capacity %d, got %d", reserved, ht.cap)
+	}
+}
+
+func TestHashTable_ResizeTiming(t *testing.T) {
+	cases := []struct {
+		name     string
+		opts     []Option
+		initial  int
+		fits     int // keys that fit before the first resize
+		capAfter int
+	}{
+		{"default", nil, 16, 11, 32},
+		{"robin hood", []Option{WithRobinHood()}, 16, 11, 32},
+		{"load 0.5", []Option{WithMaxLoadFactor(0.5)}, 16, 8, 32},
+		{"load 0.9", []Option{WithMaxLoadFactor(0.9)}, 10, 9, 20},
+		{"growth 1.5", []Option{WithGrowthFactor(1.5)}, 16, 11, 24},
+		{"power of two", []Option{WithPowerOfTwo(), WithGrowthFactor(1.5)}, 100, 89, 256},
+	}
+
+	for _, c := range cases {
+		ht := New[int, int](c.initial, c.opts...)
+		start := ht.cap
+		for i := 0; i < c.fits; i++ {
+			ht.Put(i, i)
+		}
+		if ht.cap != start {
+			t.Errorf("%s: resized early, capacity %d -> %d after %d keys", c.name, start, ht.cap, c.fits)
+			continue
+		}
+
+		// Updating a key never resizes, however full the table
+		ht.Put(0, -1)
+		if ht.cap != start {
+			t.Errorf("%s: resized on update", c.name)
+		}
+
+		ht.Put(c.fits, c.fits)
+		if ht.cap != c.capAfter {
+			t.Errorf("%s: expected capacity %d after key %d, got %d", c.name, c.capAfter, c.fits+1, ht.cap)
+		}
+		for i := 0; i <= c.fits; i++ {
+			if _, ok := ht.Get(i); !ok {
+				t.Errorf("%s: lost key %d", c.name, i)
+			}
+		}
+	}
+}
+
+func TestHashTable_TombstoneReuseDoesNotResize(t *testing.T) {
+	ht := New[int, int](16)
+	for i := 0; i < 11; i++ {
+		ht.Put(i, i)
+	}
+	// Each delete leaves a tombstone in the key's probe chain, which the
+	// next insert of that key reuses without using a free slot
+	for i := 0; i < 11; i++ {
+		ht.Delete(i)
+		ht.Put(i, i)
+	}
+	if ht.cap != 16 {
+		t.Errorf("expected capacity 16, got %d", ht.cap)
+	}
+}
+
+func TestHashTable_PowerOfTwoCapacities(t *testing.T) {
+	ht := New[int, int](5, WithPowerOfTwo(), WithGrowthFactor(3))
+	if ht.cap != 8 {
+		t.Fatalf("expected initial capacity 8, got %d", ht.cap)
+	}
+	for i := 0; i < 1000; i++ {
+		ht.Put(i, i)
+		if ht.cap&(ht.cap-1) != 0 {
+			t.Fatalf("capacity %d is not a power of two", ht.cap)
+		}
+	}
+	ht.Reserve(5000)
+	for i := 0; i < 1000; i++ {
+		ht.Delete(i)
+	}
+	ht.Compact()
+	if ht.cap != minCapacity {
+		t.Errorf("expected %d after compacting an empty table, got %d", minCapacity, ht.cap)
+	}
+}
+
+func TestHashTable_InvalidOptions(t *testing.T) {
+	for _, opt := range []Option{
+		WithMaxLoadFactor(0),
+		WithMaxLoadFactor(1),
+		WithMaxLoadFactor(-0.5),
+		WithGrowthFactor(1),
+		WithGrowthFactor(0.5),
+	} {
+		mustPanic(t, "invalid option", func() {
+			New[int, int](0, opt)
+		})
+	}
+}
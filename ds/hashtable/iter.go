@@ -0,0 +1,52 @@
+package hashtable
+
+import "iter"
+
+// All returns an iterator over the table's key-value pairs in no
+// particular order.
+//
+// The table must not be structurally modified while an iteration is in
+// progress: adding or deleting a key from the loop body makes the
+// iterator panic on its next step. Replacing the value of an existing key
+// with Put is allowed, and so is any modification right before breaking
+// out of the loop.
+func (h *HashTable[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		mods := h.mods
+		for i := 0; i < len(h.data); i++ {
+			e := &h.data[i]
+			if !e.occupied {
+				continue
+			}
+			if !yield(e.key, e.value) {
+				return
+			}
+			if h.mods != mods {
+				panic("hashtable: table modified during iteration")
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over the table's keys, with the same rules as All.
+func (h *HashTable[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range h.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the table's values, with the same rules
+// as All.
+func (h *HashTable[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range h.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
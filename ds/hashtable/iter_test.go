@@ -0,0 +1,130 @@
+package hashtable
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func mustPanic(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s: expected panic", name)
+		}
+	}()
+	f()
+}
+
+func panicMessage(f func()) (msg string) {
+	defer func() {
+		msg = fmt.Sprint(recover())
+	}()
+	f()
+	return ""
+}
+
+func TestIter_All(t *testing.T) {
+	ht := New[int, int](256)
+	expected := make(map[int]int)
+	for i := 0; i < 100; i++ {
+		ht.Put(i, i*i)
+		expected[i] = i * i
+	}
+	for i := 0; i < 100; i += 3 {
+		ht.Delete(i)
+		delete(expected, i)
+	}
+
+	if got := maps.Collect(ht.All()); !maps.Equal(got, expected) {
+		t.Errorf("All: expected %d pairs, got %d", len(expected), len(got))
+	}
+
+	keys := slices.Sorted(ht.Keys())
+	if !slices.Equal(keys, slices.Sorted(maps.Keys(expected))) {
+		t.Errorf("Keys: got %v", keys)
+	}
+	values := slices.Sorted(ht.Values())
+	if !slices.Equal(values, slices.Sorted(maps.Values(expected))) {
+		t.Errorf("Values: got %v", values)
+	}
+}
+
+func TestIter_EmptyAndBreak(t *testing.T) {
+	ht := New[string, int](0)
+	for k := range ht.All() {
+		t.Errorf("unexpected key %q in empty table", k)
+	}
+
+	ht.Put("a", 1)
+	ht.Put("b", 2)
+	ht.Put("c", 3)
+	n := 0
+	for range ht.Keys() {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("expected break after one key, got %d", n)
+	}
+}
+
+func TestIter_ModificationDuringIteration(t *testing.T) {
+	build := func() *HashTable[int, int] {
+		ht := New[int, int](16)
+		for i := 0; i < 5; i++ {
+			ht.Put(i, i)
+		}
+		return ht
+	}
+
+	ht := build()
+	mustPanic(t, "Put new key", func() {
+		for k := range ht.All() {
+			ht.Put(k+100, k)
+		}
+	})
+
+	ht = build()
+	mustPanic(t, "Delete", func() {
+		for k := range ht.Keys() {
+			ht.Delete(k)
+		}
+	})
+
+	ht = build()
+	mustPanic(t, "Delete via Values", func() {
+		for range ht.Values() {
+			ht.Delete(0)
+			ht.Delete(1)
+		}
+	})
+
+	// Replacing values is not a structural change
+	ht = build()
+	for k, v := range ht.All() {
+		ht.Put(k, v*10)
+	}
+	for i := 0; i < 5; i++ {
+		if v, _ := ht.Get(i); v != i*10 {
+			t.Errorf("key %d: expected %d, got %d", i, i*10, v)
+		}
+	}
+
+	// Modifying and then breaking out is fine
+	ht = build()
+	for k := range ht.All() {
+		ht.Delete(k)
+		break
+	}
+	if ht.Len() != 4 {
+		t.Errorf("expected 4 keys left, got %d", ht.Len())
+	}
+
+	// A failed Delete changes nothing
+	ht = build()
+	for range ht.All() {
+		ht.Delete(999)
+	}
+}
@@ -0,0 +1,104 @@
+package hashtable
+
+// Robin Hood probing. Each occupied slot records its entry's distance
+// from its home slot, and the entries along any probe sequence are kept
+// so that no entry is further from home than the ones that follow it
+// would allow. A lookup can therefore stop as soon as it meets an entry
+// that is closer to home than the key being sought would be at that slot.
+// The table never holds tombstones in this mode.
+
+func (h *HashTable[K, V]) putRobinHood(k K, v V) {
+	idx := h.hasher.Hash(k) % uint64(h.cap)
+	for dist := 0; ; dist++ {
+		e := &h.data[idx]
+		if !e.occupied || e.dist < dist {
+			// k is absent: it belongs here
+			if h.overloaded() {
+				h.resize()
+				h.putRobinHood(k, v)
+				return
+			}
+			insertRobinHood(h.data, idx, Entry[K, V]{key: k, value: v, occupied: true, dist: dist})
+			h.size++
+			h.mods++
+			return
+		}
+		if e.key == k {
+			e.value = v
+			return
+		}
+		idx = (idx + 1) % uint64(h.cap)
+	}
+}
+
+func insertRobinHood[K comparable, V any](data []Entry[K, V], idx uint64, entry Entry[K, V]) {
+	// Place entry at or after idx, where entry.dist is its distance from
+	// home at idx, displacing entries that are closer to their homes.
+	// data must have a free slot.
+	entry.occupied = true
+	for {
+		e := &data[idx]
+		if !e.occupied {
+			*e = entry
+			return
+		}
+		if e.dist < entry.dist {
+			*e, entry = entry, *e
+		}
+		idx = (idx + 1) % uint64(len(data))
+		entry.dist++
+	}
+}
+
+func (h *HashTable[K, V]) findRobinHood(k K) (uint64, bool) {
+	idx := h.hasher.Hash(k) % uint64(h.cap)
+	for dist := 0; ; dist++ {
+		e := &h.data[idx]
+		if !e.occupied || e.dist < dist {
+			return 0, false
+		}
+		if e.key == k {
+			return idx, true
+		}
+		idx = (idx + 1) % uint64(h.cap)
+	}
+}
+
+func (h *HashTable[K, V]) getRobinHood(k K) (V, bool) {
+	idx, found := h.findRobinHood(k)
+	if !found {
+		var zero V
+		return zero, false
+	}
+	return h.data[idx].value, true
+}
+
+func (h *HashTable[K, V]) deleteRobinHood(k K) bool {
+	idx, found := h.findRobinHood(k)
+	if !found {
+		return false
+	}
+	h.removeAt(idx)
+	return true
+}
+
+func (h *HashTable[K, V]) shiftBack(idx uint64) {
+	// Backward shift: pull each following displaced entry one slot closer
+	// to home until reaching an empty slot or an entry already at home
+	for {
+		next := (idx + 1) % uint64(h.cap)
+		if !h.data[next].occupied || h.data[next].dist == 0 {
+			break
+		}
+		h.data[idx] = h.data[next]
+		h.data[idx].dist--
+		idx = next
+	}
+	h.data[idx] = Entry[K, V]{}
+}
+
+func (h *HashTable[K, V]) probeDistance(idx int) int {
+	// Distance of the entry at idx from its home slot, in either mode
+	home := int(h.hasher.Hash(h.data[idx].key) % uint64(h.cap))
+	return (idx - home + h.cap) % h.cap
+}
@@ -0,0 +1,118 @@
+package hashtable
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// checkRobinHood verifies every slot's recorded distance and the Robin
+// Hood ordering: along a run, distance grows by at most one per slot.
+func checkRobinHood[K comparable, V any](t *testing.T, h *HashTable[K, V]) {
+	t.Helper()
+	live := 0
+	for i, e := range h.data {
+		if e.tombstone {
+			t.Fatalf("slot %d holds a tombstone", i)
+		}
+		if !e.occupied {
+			continue
+		}
+		live++
+		if d := h.probeDistance(i); d != e.dist {
+			t.Fatalf("slot %d records distance %d, actual %d", i, e.dist, d)
+		}
+		prev := h.data[(i-1+h.cap)%h.cap]
+		if e.dist > 0 && (!prev.occupied || e.dist > prev.dist+1) {
+			t.Fatalf("slot %d at distance %d follows a slot at distance %d", i, e.dist, prev.dist)
+		}
+	}
+	if live != h.size {
+		t.Fatalf("%d occupied slots, size %d", live, h.size)
+	}
+}
+
+func TestRobinHood_Basic(t *testing.T) {
+	ht := New[string, int](8, WithRobinHood())
+	ht.Put("foo", 1)
+	ht.Put("bar", 2)
+	ht.Put("foo", 3)
+
+	if v, ok := ht.Get("foo"); !ok || v != 3 {
+		t.Errorf("expected 3, got %d (found=%v)", v, ok)
+	}
+	if ht.Len() != 2 {
+		t.Errorf("expected len 2, got %d", ht.Len())
+	}
+	if !ht.Delete("foo") || ht.Delete("foo") {
+		t.Error("expected exactly one successful Delete(foo)")
+	}
+	if _, ok := ht.Get("foo"); ok {
+		t.Error("expected foo to be gone")
+	}
+	checkRobinHood(t, ht)
+}
+
+func TestRobinHood_Random(t *testing.T) {
+	rng := rand.New(rand.NewPCG(15, 15))
+	ht := New[int, int](4, WithRobinHood())
+	expected := make(map[int]int)
+
+	for i := 0; i < 20000; i++ {
+		k := rng.IntN(2000)
+		switch rng.IntN(3) {
+		case 0:
+			_, ok := expected[k]
+			if ht.Delete(k) != ok {
+				t.Fatalf("Delete(%d) disagreed with the model", k)
+			}
+			delete(expected, k)
+		default:
+			ht.Put(k, i)
+			expected[k] = i
+		}
+		if i%1000 == 0 {
+			checkRobinHood(t, ht)
+		}
+	}
+	checkRobinHood(t, ht)
+
+	for k := 0; k < 2000; k++ {
+		v, ok := ht.Get(k)
+		want, wantOK := expected[k]
+		if ok != wantOK || v != want {
+			t.Fatalf("key %d: found=%v, val=%v; want %v, %v", k, ok, v, wantOK, want)
+		}
+	}
+	n := 0
+	for k, v := range ht.All() {
+		if expected[k] != v {
+			t.Fatalf("All yielded %d=%d, want %d", k, v, expected[k])
+		}
+		n++
+	}
+	if n != len(expected) {
+		t.Errorf("All yielded %d keys, want %d", n, len(expected))
+	}
+}
+
+func TestRobinHood_Collisions(t *testing.T) {
+	// A constant hash puts every key in one run, exercising displacement
+	// and backward shift across the whole table
+	ht := New[int, int](16, WithRobinHood(), WithHasher[int](constHasher{}))
+	for i := 0; i < 10; i++ {
+		ht.Put(i, i)
+	}
+	for i := 0; i < 10; i += 2 {
+		ht.Delete(i)
+	}
+	checkRobinHood(t, ht)
+	for i := 0; i < 10; i++ {
+		if _, ok := ht.Get(i); ok != (i%2 == 1) {
+			t.Errorf("key %d: found=%v", i, ok)
+		}
+	}
+}
+
+type constHasher struct{}
+
+func (constHasher) Hash(int) uint64 { return 7 }
@@ -0,0 +1,115 @@
+package hashtable
+
+import "iter"
+
+// Set is a set of keys backed by a HashTable.
+type Set[K comparable] struct {
+	table *HashTable[K, struct{}]
+	// opts configure the table, and those of sets derived from this one
+	opts []Option
+}
+
+// NewSet returns an empty set. opts configure its table as they would a
+// HashTable, and are passed on to the sets Union and the other set
+// operations return.
+func NewSet[K comparable](opts ...Option) *Set[K] {
+	return &Set[K]{table: New[K, struct{}](0, opts...), opts: opts}
+}
+
+// CollectSet returns a set of the keys in seq.
+func CollectSet[K comparable](seq iter.Seq[K], opts ...Option) *Set[K] {
+	s := NewSet[K](opts...)
+	for k := range seq {
+		s.Add(k)
+	}
+	return s
+}
+
+// Add adds k to the set and reports whether it was absent.
+func (s *Set[K]) Add(k K) bool {
+	_, loaded := s.table.Swap(k, struct{}{})
+	return !loaded
+}
+
+// Remove removes k from the set and reports whether it was present.
+func (s *Set[K]) Remove(k K) bool {
+	return s.table.Delete(k)
+}
+
+func (s *Set[K]) Contains(k K) bool {
+	_, ok := s.table.Get(k)
+	return ok
+}
+
+func (s *Set[K]) Len() int {
+	return s.table.Len()
+}
+
+// All returns an iterator over the set's keys in no particular order,
+// with the same rules as HashTable.All.
+func (s *Set[K]) All() iter.Seq[K] {
+	return s.table.Keys()
+}
+
+// Union returns a new set of the keys in s, other or both.
+func (s *Set[K]) Union(other *Set[K]) *Set[K] {
+	u := NewSet[K](s.opts...)
+	for k := range s.All() {
+		u.Add(k)
+	}
+	for k := range other.All() {
+		u.Add(k)
+	}
+	return u
+}
+
+// Intersect returns a new set of the keys in both s and other.
+func (s *Set[K]) Intersect(other *Set[K]) *Set[K] {
+	i := NewSet[K](s.opts...)
+	small, large := s, other
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+	for k := range small.All() {
+		if large.Contains(k) {
+			i.Add(k)
+		}
+	}
+	return i
+}
+
+// Difference returns a new set of the keys in s but not in other.
+func (s *Set[K]) Difference(other *Set[K]) *Set[K] {
+	d := NewSet[K](s.opts...)
+	for k := range s.All() {
+		if !other.Contains(k) {
+			d.Add(k)
+		}
+	}
+	return d
+}
+
+// SymmetricDifference returns a new set of the keys in exactly one of s
+// and other.
+func (s *Set[K]) SymmetricDifference(other *Set[K]) *Set[K] {
+	d := s.Difference(other)
+	for k := range other.All() {
+		if !s.Contains(k) {
+			d.Add(k)
+		}
+	}
+	return d
+}
+
+// IsSubset reports whether every key in s is also in other.
+func (s *Set[K]) IsSubset(other *Set[K]) bool {
+	if s.Len() > other.Len() {
+		return false
+	}
+	for k := range s.All() {
+		if !other.Contains(k) {
+			return false
+		}
+	}
+	return true
+}
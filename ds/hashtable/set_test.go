@@ -0,0 +1,101 @@
+package hashtable
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func sorted(s *Set[int]) []int {
+	return slices.Sorted(s.All())
+}
+
+func TestSet_Basic(t *testing.T) {
+	s := NewSet[string]()
+	if !s.Add("a") || !s.Add("b") || s.Add("a") {
+		t.Error("expected Add to report only new keys")
+	}
+	if !s.Contains("a") || s.Contains("c") {
+		t.Error("Contains disagreed with the keys added")
+	}
+	if s.Len() != 2 {
+		t.Errorf("expected len 2, got %d", s.Len())
+	}
+	if !s.Remove("a") || s.Remove("a") {
+		t.Error("expected exactly one successful Remove(a)")
+	}
+	if got := slices.Collect(s.All()); !slices.Equal(got, []string{"b"}) {
+		t.Errorf("expected [b], got %v", got)
+	}
+}
+
+func TestSet_Collect(t *testing.T) {
+	s := CollectSet(slices.Values([]int{3, 1, 3, 2, 1}), WithRobinHood())
+	if got := sorted(s); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("expected [1 2 3], got %v", got)
+	}
+	if !s.table.robinHood {
+		t.Error("expected options to reach the table")
+	}
+
+	empty := CollectSet(maps.Keys(map[int]bool{}))
+	if empty.Len() != 0 {
+		t.Errorf("expected empty set, got %v", sorted(empty))
+	}
+}
+
+func TestSet_Algebra(t *testing.T) {
+	a := CollectSet(slices.Values([]int{1, 2, 3, 4}), WithRobinHood())
+	b := CollectSet(slices.Values([]int{3, 4, 5}))
+	empty := NewSet[int]()
+
+	tests := []struct {
+		name string
+		got  *Set[int]
+		want []int
+	}{
+		{"Union", a.Union(b), []int{1, 2, 3, 4, 5}},
+		{"Intersect", a.Intersect(b), []int{3, 4}},
+		{"Intersect reversed", b.Intersect(a), []int{3, 4}},
+		{"Difference", a.Difference(b), []int{1, 2}},
+		{"Difference reversed", b.Difference(a), []int{5}},
+		{"SymmetricDifference", a.SymmetricDifference(b), []int{1, 2, 5}},
+		{"Union empty", a.Union(empty), []int{1, 2, 3, 4}},
+		{"Intersect empty", a.Intersect(empty), nil},
+		{"Difference self", a.Difference(a), nil},
+		{"SymmetricDifference self", a.SymmetricDifference(a), nil},
+	}
+	for _, tt := range tests {
+		if got := sorted(tt.got); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+
+	// Results are new sets configured like the receiver
+	if !a.Union(b).table.robinHood || b.Union(a).table.robinHood {
+		t.Error("expected results to take the receiver's options")
+	}
+	if got := sorted(a); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("operations modified their receiver: %v", got)
+	}
+}
+
+func TestSet_IsSubset(t *testing.T) {
+	a := CollectSet(slices.Values([]int{1, 2}))
+	b := CollectSet(slices.Values([]int{1, 2, 3}))
+	c := CollectSet(slices.Values([]int{1, 4, 5}))
+	empty := NewSet[int]()
+
+	if !a.IsSubset(b) || b.IsSubset(a) {
+		t.Error("expected a to be a proper subset of b")
+	}
+	if !a.IsSubset(a) {
+		t.Error("expected a set to be a subset of itself")
+	}
+	if a.IsSubset(c) {
+		t.Error("a is not a subset of c despite c being larger")
+	}
+	if !empty.IsSubset(a) || a.IsSubset(empty) {
+		t.Error("expected only the empty set to be a subset of everything")
+	}
+}
@@ -0,0 +1,46 @@
+package hashtable
+
+// Stats describes a table's occupancy and how far its keys sit from their
+// home slots.
+type Stats struct {
+	Capacity   int
+	Live       int
+	Tombstones int
+	// LoadFactor is the fraction of slots holding live keys or tombstones,
+	// the figure compared against the maximum load factor
+	LoadFactor float64
+	// ProbeLengths[d] counts the live keys d slots past their home slot,
+	// which a lookup finds on its d+1th probe
+	ProbeLengths []int
+	MaxProbe     int
+	// Resizes counts rehashes that grew or shrank the table
+	Resizes int
+}
+
+// Stats scans the table and reports its occupancy and probe lengths. It
+// takes time proportional to the capacity and hashes every live key once,
+// or none in Robin Hood mode, and allocates only the histogram.
+func (h *HashTable[K, V]) Stats() Stats {
+	stats := Stats{
+		Capacity:   h.cap,
+		Live:       h.size,
+		Tombstones: h.tombstoneSize,
+		LoadFactor: float64(h.size+h.tombstoneSize) / float64(h.cap),
+		Resizes:    h.resizes,
+	}
+	for i := range h.data {
+		if !h.data[i].occupied {
+			continue
+		}
+		d := h.data[i].dist
+		if !h.robinHood {
+			d = h.probeDistance(i)
+		}
+		for len(stats.ProbeLengths) <= d {
+			stats.ProbeLengths = append(stats.ProbeLengths, 0)
+		}
+		stats.ProbeLengths[d]++
+		stats.MaxProbe = max(stats.MaxProbe, d)
+	}
+	return stats
+}
@@ -0,0 +1,78 @@
+package hashtable
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStats_Occupancy(t *testing.T) {
+	ht := New[int, int](100)
+	for i := 0; i < 60; i++ {
+		ht.Put(i, i)
+	}
+	for i := 0; i < 20; i++ {
+		ht.Delete(i)
+	}
+	s := ht.Stats()
+	if s.Capacity != 100 || s.Live != 40 || s.Tombstones != 20 {
+		t.Errorf("expected cap 100, 40 live, 20 tombstones, got %+v", s)
+	}
+	if s.LoadFactor != 0.6 {
+		t.Errorf("expected load factor 0.6, got %v", s.LoadFactor)
+	}
+	total := 0
+	for _, n := range s.ProbeLengths {
+		total += n
+	}
+	if total != s.Live || s.MaxProbe != len(s.ProbeLengths)-1 {
+		t.Errorf("histogram %v does not match %d live keys and max probe %d", s.ProbeLengths, s.Live, s.MaxProbe)
+	}
+
+	empty := New[int, int](0).Stats()
+	if empty.Live != 0 || empty.LoadFactor != 0 || empty.ProbeLengths != nil || empty.MaxProbe != 0 {
+		t.Errorf("unexpected stats for empty table: %+v", empty)
+	}
+}
+
+func TestStats_ProbeLengths(t *testing.T) {
+	// With one home slot for every key, the keys sit at distances 0..n-1
+	for _, opts := range [][]Option{nil, {WithRobinHood()}} {
+		ht := New[int, int](16, append(opts, WithHasher[int](constHasher{}))...)
+		for i := 0; i < 8; i++ {
+			ht.Put(i, i)
+		}
+		s := ht.Stats()
+		if want := []int{1, 1, 1, 1, 1, 1, 1, 1}; !slices.Equal(s.ProbeLengths, want) {
+			t.Errorf("expected histogram %v, got %v", want, s.ProbeLengths)
+		}
+		if s.MaxProbe != 7 {
+			t.Errorf("expected max probe 7, got %d", s.MaxProbe)
+		}
+	}
+}
+
+func TestStats_Resizes(t *testing.T) {
+	ht := New[int, int](8)
+	for i := 0; i < 100; i++ {
+		ht.Put(i, i)
+	}
+	// 8 -> 16 -> 32 -> 64 -> 128 -> 256
+	grown := ht.Stats().Resizes
+	if grown != 5 {
+		t.Errorf("expected 5 resizes growing to 100 keys, got %d", grown)
+	}
+
+	for i := 0; i < 100; i++ {
+		ht.Delete(i)
+	}
+	if s := ht.Stats(); s.Resizes <= grown || s.Capacity != 8 {
+		t.Errorf("expected shrinking back to 8 to count as resizes, got %+v", s)
+	}
+
+	// Rehashing in place does not resize
+	before := ht.Stats().Resizes
+	ht.rehash(ht.cap)
+	if after := ht.Stats().Resizes; after != before {
+		t.Errorf("in-place rehash counted as a resize: %d -> %d", before, after)
+	}
+}
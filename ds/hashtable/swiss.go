@@ -0,0 +1,223 @@
+package hashtable
+
+import "math/bits"
+
+// SwissTable is an open-addressing hash table in the style of Abseil's
+// flat_hash_map and Go's own maps. Slots come in groups of eight, each
+// with a word of eight control bytes recording whether a slot is empty,
+// deleted, or full and, if full, seven bits of its key's hash (H2). The
+// rest of the hash (H1) picks the group where probing starts. A lookup
+// compares H2 against a whole group's control word at once with SWAR
+// arithmetic and only inspects the keys of the slots that match, so most
+// probes touch a single group and very few keys.
+type SwissTable[K comparable, V any] struct {
+	groups []group[K, V]
+	// mask is len(groups)-1; the group count is a power of two
+	mask   uint64
+	size   int
+	hasher Hasher[K]
+	// growthLeft is how many empty slots may still be filled before the
+	// table exceeds its 7/8 load limit
+	growthLeft int
+}
+
+type group[K comparable, V any] struct {
+	ctrl   uint64
+	keys   [groupSize]K
+	values [groupSize]V
+}
+
+const (
+	groupSize = 8
+
+	ctrlEmpty   = 0x80
+	ctrlDeleted = 0xFE
+
+	lsb = 0x0101010101010101
+	msb = 0x8080808080808080
+)
+
+// NewSwiss returns an empty SwissTable with room for at least initialCap
+// keys. Of the options, only WithHasher applies.
+func NewSwiss[K comparable, V any](initialCap int, opts ...Option) *SwissTable[K, V] {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	s := &SwissTable[K, V]{hasher: resolveHasher[K](cfg)}
+	s.init(swissGroupsFor(initialCap))
+	return s
+}
+
+func swissGroupsFor(n int) int {
+	// Fewest groups, a power of two, holding n keys at 7/8 load
+	slots := (n*8 + 6) / 7
+	return roundUpPow2(max((slots+groupSize-1)/groupSize, 1))
+}
+
+func (s *SwissTable[K, V]) init(groups int) {
+	s.groups = make([]group[K, V], groups)
+	for i := range s.groups {
+		s.groups[i].ctrl = lsb * ctrlEmpty
+	}
+	s.mask = uint64(groups - 1)
+	s.growthLeft = groups * groupSize * 7 / 8
+}
+
+func (s *SwissTable[K, V]) Len() int {
+	return s.size
+}
+
+func splitHash(hash uint64) (h1 uint64, h2 uint8) {
+	return hash >> 7, uint8(hash & 0x7F)
+}
+
+// matchH2 returns a bitset with the high bit of each control byte equal
+// to h2 set. It can report false positives next to a true match (from the
+// borrow in the subtraction), which the caller's key comparison weeds out.
+func matchH2(ctrl uint64, h2 uint8) uint64 {
+	v := ctrl ^ (lsb * uint64(h2))
+	return (v - lsb) &^ v & msb
+}
+
+func matchEmpty(ctrl uint64) uint64 {
+	// Empty (0x80) is the only control byte with bit 7 set and bit 1 clear
+	return ctrl &^ (ctrl << 6) & msb
+}
+
+func matchEmptyOrDeleted(ctrl uint64) uint64 {
+	return ctrl & msb
+}
+
+func setCtrl(ctrl *uint64, slot int, b uint8) {
+	shift := uint(slot) * 8
+	*ctrl = *ctrl&^(0xFF<<shift) | uint64(b)<<shift
+}
+
+// find returns the group and slot holding k.
+func (s *SwissTable[K, V]) find(k K) (*group[K, V], int, bool) {
+	h1, h2 := splitHash(s.hasher.Hash(k))
+	// Triangular probing visits every group once when the count is a power of two
+	for g, step := h1&s.mask, uint64(1); ; g, step = (g+step)&s.mask, step+1 {
+		grp := &s.groups[g]
+		for m := matchH2(grp.ctrl, h2); m != 0; m &= m - 1 {
+			slot := bits.TrailingZeros64(m) >> 3
+			if grp.keys[slot] == k {
+				return grp, slot, true
+			}
+		}
+		if matchEmpty(grp.ctrl) != 0 {
+			return nil, 0, false
+		}
+	}
+}
+
+func (s *SwissTable[K, V]) Get(k K) (V, bool) {
+	grp, slot, ok := s.find(k)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return grp.values[slot], true
+}
+
+func (s *SwissTable[K, V]) Put(k K, v V) {
+	hash := s.hasher.Hash(k)
+	h1, h2 := splitHash(hash)
+
+	// Look for k, remembering the first free slot on the way in case it
+	// is absent
+	var free *group[K, V]
+	freeSlot := 0
+	for g, step := h1&s.mask, uint64(1); ; g, step = (g+step)&s.mask, step+1 {
+		grp := &s.groups[g]
+		for m := matchH2(grp.ctrl, h2); m != 0; m &= m - 1 {
+			slot := bits.TrailingZeros64(m) >> 3
+			if grp.keys[slot] == k {
+				grp.values[slot] = v
+				return
+			}
+		}
+		if free == nil {
+			if m := matchEmptyOrDeleted(grp.ctrl); m != 0 {
+				free, freeSlot = grp, bits.TrailingZeros64(m)>>3
+			}
+		}
+		if matchEmpty(grp.ctrl) != 0 {
+			break
+		}
+	}
+
+	// Reusing a deleted slot costs nothing; taking an empty one uses up growth
+	wasEmpty := uint8(free.ctrl>>(uint(freeSlot)*8)) == ctrlEmpty
+	if wasEmpty && s.growthLeft == 0 {
+		s.rehash()
+		s.Put(k, v)
+		return
+	}
+	if wasEmpty {
+		s.growthLeft--
+	}
+	setCtrl(&free.ctrl, freeSlot, h2)
+	free.keys[freeSlot] = k
+	free.values[freeSlot] = v
+	s.size++
+}
+
+func (s *SwissTable[K, V]) Delete(k K) bool {
+	grp, slot, ok := s.find(k)
+	if !ok {
+		return false
+	}
+	var zeroK K
+	var zeroV V
+	grp.keys[slot] = zeroK
+	grp.values[slot] = zeroV
+
+	// A group that still has an empty slot never made a probe move past
+	// it, so the slot can go straight back to empty; otherwise leave a
+	// tombstone to keep later probes going
+	if matchEmpty(grp.ctrl) != 0 {
+		setCtrl(&grp.ctrl, slot, ctrlEmpty)
+		s.growthLeft++
+	} else {
+		setCtrl(&grp.ctrl, slot, ctrlDeleted)
+	}
+	s.size--
+	return true
+}
+
+func (s *SwissTable[K, V]) rehash() {
+	// Out of growth: tombstones are counted against it, so rebuilding at
+	// the same size may be enough; otherwise double
+	groups := len(s.groups)
+	if s.size >= groups*groupSize*7/16 {
+		groups *= 2
+	}
+
+	old := s.groups
+	s.init(groups)
+	for i := range old {
+		grp := &old[i]
+		for m := ^matchEmptyOrDeleted(grp.ctrl) & msb; m != 0; m &= m - 1 {
+			slot := bits.TrailingZeros64(m) >> 3
+			s.insertNew(grp.keys[slot], grp.values[slot])
+		}
+	}
+}
+
+func (s *SwissTable[K, V]) insertNew(k K, v V) {
+	// Insert a key known to be absent into a table without tombstones
+	h1, h2 := splitHash(s.hasher.Hash(k))
+	for g, step := h1&s.mask, uint64(1); ; g, step = (g+step)&s.mask, step+1 {
+		grp := &s.groups[g]
+		if m := matchEmpty(grp.ctrl); m != 0 {
+			slot := bits.TrailingZeros64(m) >> 3
+			setCtrl(&grp.ctrl, slot, h2)
+			grp.keys[slot] = k
+			grp.values[slot] = v
+			s.growthLeft--
+			return
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package hashtable
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestSwiss_Basic(t *testing.T) {
+	s := NewSwiss[string, int](0)
+	if s.Len() != 0 {
+		t.Errorf("expected len 0, got %d", s.Len())
+	}
+
+	s.Put("foo", 1)
+	s.Put("bar", 2)
+	s.Put("foo", 3)
+	if v, ok := s.Get("foo"); !ok || v != 3 {
+		t.Errorf("expected 3, got %d (found=%v)", v, ok)
+	}
+	if _, ok := s.Get("baz"); ok {
+		t.Error("expected baz to be missing")
+	}
+	if s.Len() != 2 {
+		t.Errorf("expected len 2, got %d", s.Len())
+	}
+
+	if !s.Delete("foo") || s.Delete("foo") {
+		t.Error("expected exactly one successful Delete(foo)")
+	}
+	if _, ok := s.Get("foo"); ok {
+		t.Error("expected foo to be gone")
+	}
+	if s.Len() != 1 {
+		t.Errorf("expected len 1, got %d", s.Len())
+	}
+}
+
+func TestSwiss_Random(t *testing.T) {
+	rng := rand.New(rand.NewPCG(19, 19))
+	s := NewSwiss[int, int](0)
+	expected := make(map[int]int)
+
+	for i := 0; i < 50000; i++ {
+		k := rng.IntN(3000)
+		if rng.IntN(3) == 0 {
+			_, ok := expected[k]
+			if s.Delete(k) != ok {
+				t.Fatalf("Delete(%d) disagreed with the model", k)
+			}
+			delete(expected, k)
+		} else {
+			s.Put(k, i)
+			expected[k] = i
+		}
+	}
+
+	if s.Len() != len(expected) {
+		t.Fatalf("expected len %d, got %d", len(expected), s.Len())
+	}
+	for k := 0; k < 3000; k++ {
+		v, ok := s.Get(k)
+		want, wantOK := expected[k]
+		if ok != wantOK || v != want {
+			t.Fatalf("key %d: found=%v, val=%v; want %v, %v", k, ok, v, wantOK, want)
+		}
+	}
+}
+
+func TestSwiss_Collisions(t *testing.T) {
+	// Every key has the same H1 and H2, so every slot matches and probing
+	// has to walk the groups in order
+	s := NewSwiss[int, int](0, WithHasher[int](constHasher{}))
+	for i := 0; i < 100; i++ {
+		s.Put(i, i)
+	}
+	for i := 0; i < 100; i += 2 {
+		s.Delete(i)
+	}
+	for i := 100; i < 150; i++ {
+		s.Put(i, i)
+	}
+	for i := 0; i < 150; i++ {
+		if _, ok := s.Get(i); ok != (i%2 == 1 || i >= 100) {
+			t.Errorf("key %d: found=%v", i, ok)
+		}
+	}
+}
+
+func TestSwiss_TombstonesDoNotGrow(t *testing.T) {
+	s := NewSwiss[int, int](100)
+	groups := len(s.groups)
+	// Churn far more keys through the table than it can hold
+	for i := 0; i < 10000; i++ {
+		s.Put(i, i)
+		if i >= 50 {
+			s.Delete(i - 50)
+		}
+	}
+	if len(s.groups) != groups {
+		t.Errorf("expected %d groups, got %d", groups, len(s.groups))
+	}
+	if s.Len() != 50 {
+		t.Errorf("expected len 50, got %d", s.Len())
+	}
+}
+
+func TestSwiss_Sizing(t *testing.T) {
+	for n, want := range map[int]int{0: 1, 7: 1, 8: 2, 100: 16, 1000: 256} {
+		if got := swissGroupsFor(n); got != want {
+			t.Errorf("swissGroupsFor(%d) = %d, want %d", n, got, want)
+		}
+	}
+
+	s := NewSwiss[int, int](1000)
+	groups := len(s.groups)
+	for i := 0; i < 1000; i++ {
+		s.Put(i, i)
+	}
+	if len(s.groups) != groups {
+		t.Errorf("expected no growth within initial capacity, %d -> %d groups", groups, len(s.groups))
+	}
+}
+
+func TestSwiss_Match(t *testing.T) {
+	// Slots 0..7: full 0x12, empty, deleted, full 0x12, full 0x00, empty, full 0x7F, deleted
+	ctrl := uint64(0)
+	for i, b := range []uint8{0x12, ctrlEmpty, ctrlDeleted, 0x12, 0x00, ctrlEmpty, 0x7F, ctrlDeleted} {
+		setCtrl(&ctrl, i, b)
+	}
+	slots := func(m uint64) []int {
+		var out []int
+		for i := 0; i < 8; i++ {
+			if m&(0x80<<(i*8)) != 0 {
+				out = append(out, i)
+			}
+		}
+		return out
+	}
+
+	if got := slots(matchH2(ctrl, 0x12)); !slices.Equal(got, []int{0, 3}) {
+		t.Errorf("matchH2(0x12) = %v", got)
+	}
+	if got := slots(matchH2(ctrl, 0x7F)); !slices.Equal(got, []int{6}) {
+		t.Errorf("matchH2(0x7F) = %v", got)
+	}
+	if got := slots(matchEmpty(ctrl)); !slices.Equal(got, []int{1, 5}) {
+		t.Errorf("matchEmpty = %v", got)
+	}
+	if got := slots(matchEmptyOrDeleted(ctrl)); !slices.Equal(got, []int{1, 2, 5, 7}) {
+		t.Errorf("matchEmptyOrDeleted = %v", got)
+	}
+}
@@ -0,0 +1,151 @@
+package hashtable
+
+// Single-probe read-modify-write operations. Each one finds the key's slot,
+// or the slot it would be inserted at, once and then works on that slot
+// directly instead of calling Get and then Put.
+
+// GetOrInsert returns the value under k and true if k is present.
+// Otherwise it stores fn() under k and returns it with false.
+//
+// fn may use the table, though doing so costs a second probe.
+func (h *HashTable[K, V]) GetOrInsert(k K, fn func() V) (V, bool) {
+	idx, dist, found := h.probe(k)
+	if found {
+		return h.data[idx].value, true
+	}
+	mods := h.mods
+	v := fn()
+	if h.mods != mods {
+		idx, dist, found = h.probe(k)
+		if found {
+			h.data[idx].value = v
+			return v, false
+		}
+	}
+	h.insertAt(idx, dist, k, v)
+	return v, false
+}
+
+// Update calls fn with the value under k and whether k is present, and
+// stores the value fn returns, or deletes k if fn returns false. It
+// returns the value fn returned and whether k was present beforehand.
+//
+// fn may use the table, though doing so costs a second probe.
+func (h *HashTable[K, V]) Update(k K, fn func(old V, loaded bool) (V, bool)) (V, bool) {
+	idx, dist, loaded := h.probe(k)
+	var old V
+	if loaded {
+		old = h.data[idx].value
+	}
+	mods := h.mods
+	v, keep := fn(old, loaded)
+
+	found := loaded
+	if h.mods != mods {
+		idx, dist, found = h.probe(k)
+	}
+	switch {
+	case keep && found:
+		h.data[idx].value = v
+	case keep:
+		h.insertAt(idx, dist, k, v)
+	case found:
+		h.removeAt(idx)
+	}
+	return v, loaded
+}
+
+// Swap stores v under k and returns the previous value and whether there
+// was one.
+func (h *HashTable[K, V]) Swap(k K, v V) (V, bool) {
+	idx, dist, found := h.probe(k)
+	if found {
+		old := h.data[idx].value
+		h.data[idx].value = v
+		return old, true
+	}
+	h.insertAt(idx, dist, k, v)
+	var zero V
+	return zero, false
+}
+
+// CompareAndSwap stores new under k if k is present with the value old.
+// It reports whether it swapped and whether k was present. It is a
+// function rather than a method because it needs comparable values.
+func CompareAndSwap[K, V comparable](h *HashTable[K, V], k K, old, new V) (swapped, loaded bool) {
+	idx, _, found := h.probe(k)
+	if !found {
+		return false, false
+	}
+	if h.data[idx].value != old {
+		return false, true
+	}
+	h.data[idx].value = new
+	return true, true
+}
+
+// probe returns the slot holding k and true, or the slot k would be
+// inserted at and false. In Robin Hood mode dist is the distance from
+// home at that slot.
+func (h *HashTable[K, V]) probe(k K) (idx uint64, dist int, found bool) {
+	idx = h.hasher.Hash(k) % uint64(h.cap)
+	if h.robinHood {
+		for ; ; dist++ {
+			e := &h.data[idx]
+			if !e.occupied || e.dist < dist {
+				return idx, dist, false
+			}
+			if e.key == k {
+				return idx, dist, true
+			}
+			idx = (idx + 1) % uint64(h.cap)
+		}
+	}
+
+	firstTombstone := -1
+	for i := 0; i < h.cap; i++ {
+		e := &h.data[idx]
+		if e.occupied {
+			if e.key == k {
+				return idx, 0, true
+			}
+		} else if e.tombstone {
+			if firstTombstone < 0 {
+				firstTombstone = int(idx)
+			}
+		} else {
+			break
+		}
+		idx = (idx + 1) % uint64(h.cap)
+	}
+	if firstTombstone >= 0 {
+		return uint64(firstTombstone), 0, false
+	}
+	return idx, 0, false
+}
+
+// insertAt inserts k, which must be absent, at the slot probe returned
+// for it, growing the table first if that would exceed the load factor.
+func (h *HashTable[K, V]) insertAt(idx uint64, dist int, k K, v V) {
+	e := &h.data[idx]
+	if e.tombstone {
+		// Reusing a tombstone never changes the load
+		*e = Entry[K, V]{key: k, value: v, occupied: true}
+		h.tombstoneSize--
+		h.size++
+		h.mods++
+		return
+	}
+	if h.overloaded() {
+		// Resizing clears tombstones, so k now goes in a free slot
+		h.resize()
+		idx, dist, _ = h.probe(k)
+	}
+	if h.robinHood {
+		insertRobinHood(h.data, idx, Entry[K, V]{key: k, value: v, dist: dist})
+	} else {
+		h.data[idx] = Entry[K, V]{key: k, value: v, occupied: true}
+	}
+	h.size++
+	h.mods++
+}
@@ -0,0 +1,185 @@
+package hashtable
+
+import (
+	"maps"
+	"math/rand/v2"
+	"testing"
+)
+
+var modes = map[string][]Option{
+	"linear":    nil,
+	"robinhood": {WithRobinHood()},
+}
+
+func TestUpsert_GetOrInsert(t *testing.T) {
+	for name, opts := range modes {
+		ht := New[string, int](0, opts...)
+		calls := 0
+		next := func() int { calls++; return calls }
+
+		if v, ok := ht.GetOrInsert("a", next); ok || v != 1 {
+			t.Errorf("%s: first GetOrInsert returned %d, %v", name, v, ok)
+		}
+		if v, ok := ht.GetOrInsert("a", next); !ok || v != 1 || calls != 1 {
+			t.Errorf("%s: second GetOrInsert returned %d, %v after %d calls", name, v, ok, calls)
+		}
+		if ht.Len() != 1 {
+			t.Errorf("%s: expected 1 key, got %d", name, ht.Len())
+		}
+	}
+}
+
+func TestUpsert_Update(t *testing.T) {
+	for name, opts := range modes {
+		ht := New[string, int](0, opts...)
+		incr := func(old int, loaded bool) (int, bool) { return old + 1, true }
+
+		for i := 0; i < 3; i++ {
+			if v, loaded := ht.Update("n", incr); v != i+1 || loaded != (i > 0) {
+				t.Errorf("%s: Update %d returned %d, %v", name, i, v, loaded)
+			}
+		}
+		drop := func(int, bool) (int, bool) { return 0, false }
+		if _, loaded := ht.Update("n", drop); !loaded {
+			t.Errorf("%s: expected n to be present before delete", name)
+		}
+		if _, ok := ht.Get("n"); ok || ht.Len() != 0 {
+			t.Errorf("%s: expected n to be deleted", name)
+		}
+		if _, loaded := ht.Update("missing", drop); loaded || ht.Len() != 0 {
+			t.Errorf("%s: deleting an absent key changed the table", name)
+		}
+	}
+}
+
+func TestUpsert_Swap(t *testing.T) {
+	for name, opts := range modes {
+		ht := New[string, int](0, opts...)
+		if old, loaded := ht.Swap("a", 1); loaded || old != 0 {
+			t.Errorf("%s: Swap on absent key returned %d, %v", name, old, loaded)
+		}
+		if old, loaded := ht.Swap("a", 2); !loaded || old != 1 {
+			t.Errorf("%s: Swap returned %d, %v", name, old, loaded)
+		}
+
+		if swapped, loaded := CompareAndSwap(ht, "a", 1, 3); swapped || !loaded {
+			t.Errorf("%s: CompareAndSwap with stale value returned %v, %v", name, swapped, loaded)
+		}
+		if swapped, loaded := CompareAndSwap(ht, "a", 2, 3); !swapped || !loaded {
+			t.Errorf("%s: CompareAndSwap returned %v, %v", name, swapped, loaded)
+		}
+		if swapped, loaded := CompareAndSwap(ht, "b", 0, 1); swapped || loaded {
+			t.Errorf("%s: CompareAndSwap on absent key returned %v, %v", name, swapped, loaded)
+		}
+		if v, _ := ht.Get("a"); v != 3 || ht.Len() != 1 {
+			t.Errorf("%s: expected a=3 and 1 key, got %d and %d", name, v, ht.Len())
+		}
+	}
+}
+
+func TestUpsert_Random(t *testing.T) {
+	// Mix every operation on a small key space so inserts hit tombstones,
+	// deletes trigger shrinking and inserts trigger growth
+	for name, opts := range modes {
+		rng := rand.New(rand.NewPCG(3, 4))
+		ht := New[int, int](8, opts...)
+		ref := make(map[int]int)
+		for i := 0; i < 20000; i++ {
+			k := rng.IntN(300)
+			old, had := ref[k]
+			switch rng.IntN(5) {
+			case 0:
+				v, loaded := ht.GetOrInsert(k, func() int { return i })
+				if !had {
+					ref[k] = i
+				}
+				if loaded != had || v != ref[k] {
+					t.Fatalf("%s: GetOrInsert(%d) = %d, %v", name, k, v, loaded)
+				}
+			case 1:
+				keep := rng.IntN(2) == 0
+				_, loaded := ht.Update(k, func(v int, loaded bool) (int, bool) {
+					if v != old || loaded != had {
+						t.Fatalf("%s: Update(%d) saw %d, %v", name, k, v, loaded)
+					}
+					return v + i, keep
+				})
+				if loaded != had {
+					t.Fatalf("%s: Update(%d) loaded = %v", name, k, loaded)
+				}
+				if keep {
+					ref[k] = old + i
+				} else {
+					delete(ref, k)
+				}
+			case 2:
+				if prev, loaded := ht.Swap(k, i); loaded != had || prev != old {
+					t.Fatalf("%s: Swap(%d) = %d, %v", name, k, prev, loaded)
+				}
+				ref[k] = i
+			case 3:
+				swapped, loaded := CompareAndSwap(ht, k, i%7, -i)
+				if loaded != had || swapped != (had && old == i%7) {
+					t.Fatalf("%s: CompareAndSwap(%d) = %v, %v", name, k, swapped, loaded)
+				}
+				if swapped {
+					ref[k] = -i
+				}
+			case 4:
+				ht.Delete(k)
+				delete(ref, k)
+			}
+		}
+		if got := maps.Collect(ht.All()); !maps.Equal(got, ref) {
+			t.Fatalf("%s: table has %d keys, expected %d", name, len(got), len(ref))
+		}
+		if opts != nil {
+			checkRobinHood(t, ht)
+		}
+	}
+}
+
+func TestUpsert_CallbackModifiesTable(t *testing.T) {
+	for name, opts := range modes {
+		ht := New[int, int](8, opts...)
+		ht.Put(0, 0)
+
+		// The callback's own inserts force the table to grow under it
+		v, loaded := ht.GetOrInsert(1, func() int {
+			for i := 2; i < 100; i++ {
+				ht.Put(i, i)
+			}
+			return 1
+		})
+		if loaded || v != 1 {
+			t.Errorf("%s: GetOrInsert returned %d, %v", name, v, loaded)
+		}
+
+		// Inserting the key being updated, then asking to delete it
+		ht.Update(200, func(int, bool) (int, bool) {
+			ht.Put(200, 200)
+			return 0, false
+		})
+		// Deleting the key being updated, then asking to keep it
+		ht.Update(0, func(old int, loaded bool) (int, bool) {
+			ht.Delete(0)
+			return 5, true
+		})
+
+		if ht.Len() != 100 {
+			t.Errorf("%s: expected 100 keys, got %d", name, ht.Len())
+		}
+		for k := 0; k < 100; k++ {
+			want := k
+			if k == 0 {
+				want = 5
+			}
+			if v, ok := ht.Get(k); !ok || v != want {
+				t.Errorf("%s: key %d: got %d, %v", name, k, v, ok)
+			}
+		}
+		if _, ok := ht.Get(200); ok {
+			t.Errorf("%s: key 200 should have been deleted", name)
+		}
+	}
+}
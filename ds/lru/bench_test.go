@@ -0,0 +1,67 @@
+package lru
+
+import (
+	"math/rand/v2"
+	"sync"
+	"testing"
+)
+
+const benchKeys = 100000
+
+// lockedCache is the mutex-wrapped Cache that ShardedCache replaces.
+type lockedCache[K comparable, V any] struct {
+	mu    sync.Mutex
+	cache *Cache[K, V]
+}
+
+func (l *lockedCache[K, V]) Get(key K) (V, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cache.Get(key)
+}
+
+func (l *lockedCache[K, V]) Put(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cache.Put(key, value)
+}
+
+type cache interface {
+	Get(key int) (int, bool)
+	Put(key, value int)
+}
+
+func benchParallel(b *testing.B, c cache, writePct int) {
+	// Keys range over twice the capacity, so misses lead to evictions
+	for i := 0; i < benchKeys/2; i++ {
+		c.Put(i, i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		rng := rand.New(rand.NewPCG(rand.Uint64(), 0))
+		for pb.Next() {
+			k := rng.IntN(benchKeys)
+			if rng.IntN(100) < writePct {
+				c.Put(k, k)
+			} else if _, ok := c.Get(k); !ok {
+				c.Put(k, k)
+			}
+		}
+	})
+}
+
+func BenchmarkShardedCache_ReadHeavy(b *testing.B) {
+	benchParallel(b, NewSharded[int, int](benchKeys/2, 0), 10)
+}
+
+func BenchmarkLockedCache_ReadHeavy(b *testing.B) {
+	benchParallel(b, &lockedCache[int, int]{cache: New[int, int](benchKeys / 2)}, 10)
+}
+
+func BenchmarkShardedCache_WriteHeavy(b *testing.B) {
+	benchParallel(b, NewSharded[int, int](benchKeys/2, 0), 50)
+}
+
+func BenchmarkLockedCache_WriteHeavy(b *testing.B) {
+	benchParallel(b, &lockedCache[int, int]{cache: New[int, int](benchKeys / 2)}, 50)
+}
@@ -3,7 +3,7 @@ package lru
 import "container/list"
 
 // Cache represents an LRU Cache.
-// It is not thread-safe.
+// It is not thread-safe; use ShardedCache for concurrent access.
 type Cache[K comparable, V any] struct {
 	// cap is the maximum number of items.
 	cap int
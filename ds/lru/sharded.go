@@ -0,0 +1,93 @@
+package lru
+
+import (
+	"hash/maphash"
+	"runtime"
+	"sync"
+)
+
+// ShardedCache is an LRU cache that is safe for concurrent use. Keys are
+// spread over shards by hash, each a Cache behind its own mutex, so
+// goroutines using keys in different shards never contend, even though
+// every Get updates recency.
+//
+// Each shard holds an equal share of the capacity and evicts its own
+// least recently used key when full, so the cache as a whole only
+// approximates LRU order: a key can be evicted from a busy shard while an
+// older key survives in a quiet one.
+type ShardedCache[K comparable, V any] struct {
+	shards []shard[K, V]
+	seed   maphash.Seed
+	// mask turns a hash into a shard index
+	mask uint64
+}
+
+type shard[K comparable, V any] struct {
+	mu    sync.Mutex
+	cache *Cache[K, V]
+	// Keep each shard on its own cache line
+	_ [48]byte
+}
+
+// NewSharded returns an empty cache holding at most capacity keys, split
+// over the given number of shards rounded up to a power of two. If shards
+// is 0 or less it defaults to four per CPU. There are never more shards
+// than capacity.
+func NewSharded[K comparable, V any](capacity, shards int) *ShardedCache[K, V] {
+	if capacity < 1 {
+		panic("lru: capacity must be positive")
+	}
+	if shards <= 0 {
+		shards = 4 * runtime.GOMAXPROCS(0)
+	}
+	n := 1
+	for n < shards && n*2 <= capacity {
+		n *= 2
+	}
+
+	c := &ShardedCache[K, V]{
+		shards: make([]shard[K, V], n),
+		seed:   maphash.MakeSeed(),
+		mask:   uint64(n - 1),
+	}
+	for i := range c.shards {
+		// Spread the remainder so the shard capacities add up to capacity
+		share := capacity / n
+		if i < capacity%n {
+			share++
+		}
+		c.shards[i].cache = New[K, V](share)
+	}
+	return c
+}
+
+func (c *ShardedCache[K, V]) shardFor(key K) *shard[K, V] {
+	return &c.shards[maphash.Comparable(c.seed, key)&c.mask]
+}
+
+// Len returns the number of keys. It locks each shard in turn, so under
+// concurrent writes the result is approximate.
+func (c *ShardedCache[K, V]) Len() int {
+	n := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		n += s.cache.Len()
+		s.mu.Unlock()
+	}
+	return n
+}
+
+func (c *ShardedCache[K, V]) Get(key K) (V, bool) {
+	s := c.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache.Get(key)
+}
+
+func (c *ShardedCache[K, V]) Put(key K, value V) {
+	s := c.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache.Put(key, value)
+}
@@ -0,0 +1,113 @@
+package lru
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSharded_Basic(t *testing.T) {
+	c := NewSharded[string, int](100, 4)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("a", 10)
+
+	if v, ok := c.Get("a"); !ok || v != 10 {
+		t.Errorf("expected 10, got %v", v)
+	}
+	if v, ok := c.Get("b"); !ok || v != 2 {
+		t.Errorf("expected 2, got %v", v)
+	}
+	if _, ok := c.Get("c"); ok {
+		t.Error("expected 'c' to be missing")
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected len 2, got %d", c.Len())
+	}
+}
+
+func TestSharded_Capacity(t *testing.T) {
+	for _, tt := range []struct{ capacity, shards, want int }{
+		{100, 4, 4},
+		{100, 5, 8},
+		{3, 16, 2},
+		{1, 0, 1},
+	} {
+		c := NewSharded[int, int](tt.capacity, tt.shards)
+		if len(c.shards) != tt.want {
+			t.Errorf("NewSharded(%d, %d): expected %d shards, got %d", tt.capacity, tt.shards, tt.want, len(c.shards))
+		}
+		total := 0
+		for i := range c.shards {
+			total += c.shards[i].cache.cap
+		}
+		if total != tt.capacity {
+			t.Errorf("NewSharded(%d, %d): shard capacities add up to %d", tt.capacity, tt.shards, total)
+		}
+
+		for i := 0; i < 10*tt.capacity; i++ {
+			c.Put(i, i)
+		}
+		if c.Len() != tt.capacity {
+			t.Errorf("NewSharded(%d, %d): expected a full cache to hold %d keys, got %d", tt.capacity, tt.shards, tt.capacity, c.Len())
+		}
+	}
+}
+
+func TestSharded_EvictsLeastRecentlyUsed(t *testing.T) {
+	// With one shard the cache is exactly LRU
+	c := NewSharded[string, int](2, 1)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Get("a")
+	c.Put("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("expected 'b' to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("expected 'a' to stay")
+	}
+}
+
+func TestSharded_Concurrent(t *testing.T) {
+	// Run with -race. Every value is derived from its key, so a reader
+	// seeing anything else has observed a torn or misrouted write.
+	const capacity = 512
+	c := NewSharded[string, int](capacity, 8)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 5000; i++ {
+				k := (i*31 + g*7) % 2000
+				key := fmt.Sprintf("key-%d", k)
+				if i%4 == 0 {
+					c.Put(key, k)
+				} else if v, ok := c.Get(key); ok && v != k {
+					t.Errorf("Get(%s) = %d", key, v)
+					return
+				}
+				if i%500 == 0 && c.Len() > capacity {
+					t.Errorf("cache holds %d keys, capacity %d", c.Len(), capacity)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if c.Len() > capacity {
+		t.Errorf("cache holds %d keys, capacity %d", c.Len(), capacity)
+	}
+}
+
+func TestSharded_InvalidCapacity(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for capacity 0")
+		}
+	}()
+	NewSharded[int, int](0, 4)
+}